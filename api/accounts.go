@@ -0,0 +1,81 @@
+package api
+
+import (
+	"math/big"
+	"net/http"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// accountAPI is the API projection of a common.Account
+type accountAPI struct {
+	Idx     common.Idx         `json:"accountIndex"`
+	BJJ     *babyjub.PublicKey `json:"bjj"`
+	EthAddr ethCommon.Address  `json:"hezEthereumAddress"`
+	TokenID common.TokenID     `json:"tokenId"`
+	Nonce   common.Nonce       `json:"nonce"`
+	Balance string             `json:"balance"`
+}
+
+// accountsAPI is the response of getAccounts
+type accountsAPI struct {
+	Accounts   []accountAPI   `json:"accounts"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+func accountToAPI(acc common.Account) accountAPI {
+	balance := acc.Balance
+	if balance == nil {
+		balance = big.NewInt(0)
+	}
+	return accountAPI{
+		Idx:     acc.Idx,
+		BJJ:     acc.BJJ,
+		EthAddr: acc.EthAddr,
+		TokenID: acc.TokenID,
+		Nonce:   acc.Nonce,
+		Balance: balance.String(),
+	}
+}
+
+func (a *API) getAccounts(c *gin.Context) {
+	// Account filters, same as getExits
+	tokenID, addr, bjj, _, err := parseAccountFilters(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	fromItem, order, limit, err := parsePagination(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	accs, pagination, err := a.h.GetAccountsAPI(tokenID, addr, bjj, fromItem, limit, order)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	apiAccs := make([]accountAPI, len(accs))
+	for i, acc := range accs {
+		apiAccs[i] = accountToAPI(acc)
+	}
+	c.JSON(http.StatusOK, accountsAPI{Accounts: apiAccs, Pagination: pagination})
+}
+
+func (a *API) getAccount(c *gin.Context) {
+	idx, err := parseParamIdx(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	acc, err := a.h.GetAccountAPI(*idx)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, accountToAPI(*acc))
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hermeznetwork/hermez-node/api/parsers"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+var (
+	// ErrAtomicGroupNotReplaceable is returned when a PUT replacement
+	// doesn't address the same set of FromIdx as the original group, or
+	// doesn't strictly increase Nonce and Fee on every tx
+	ErrAtomicGroupNotReplaceable = errors.New("replacement group must keep the same set " +
+		"of FromIdx as the original, each with a strictly greater Nonce and Fee")
+	// ErrAtomicGroupCancelSignatureMissing is returned when a DELETE
+	// cancellation is missing a signature from one of the group's
+	// distinct FromBJJ signers
+	ErrAtomicGroupCancelSignatureMissing = errors.New("missing a signature from every " +
+		"distinct FromBJJ among the atomic group's txs")
+	// ErrAtomicGroupCancelSignatureInvalid is returned when a DELETE
+	// cancellation includes a signature that doesn't verify against the
+	// AtomicGroupID
+	ErrAtomicGroupCancelSignatureInvalid = errors.New("invalid signature over the atomic group ID")
+)
+
+// BJJSignature pairs a BabyJubJub public key with a signature over an
+// AtomicGroupID, used by deleteAtomicGroup to prove every distinct
+// signer in the group authorizes cancelling it.
+type BJJSignature struct {
+	FromBJJ   *babyjub.PublicKey    `json:"fromBJJ" binding:"required"`
+	Signature babyjub.SignatureComp `json:"signature" binding:"required"`
+}
+
+// verify reports whether sig is a valid Poseidon signature by FromBJJ
+// over atomicGroupID
+func (sig BJJSignature) verify(atomicGroupID common.AtomicGroupID) bool {
+	decompressed, err := sig.Signature.Decompress()
+	if err != nil {
+		return false
+	}
+	msg := new(big.Int).SetBytes(atomicGroupID[:])
+	return sig.FromBJJ.VerifyPoseidon(msg, decompressed)
+}
+
+// atomicGroupCancellation is the request body of deleteAtomicGroup
+type atomicGroupCancellation struct {
+	Signatures []BJJSignature `json:"signatures" binding:"required"`
+}
+
+// deleteAtomicGroup handles DELETE /atomic-pool/:atomicGroupId: cancels a
+// still-pending atomic group, once every distinct FromBJJ among its txs
+// has signed off on the AtomicGroupID, by marking all its txs
+// not-forgeable.
+func (a *API) deleteAtomicGroup(c *gin.Context) {
+	atomicGroupID, err := parsers.ParseParamAtomicGroupID(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	var cancellation atomicGroupCancellation
+	if err := c.ShouldBindJSON(&cancellation); err != nil {
+		retBadReq(err, c)
+		return
+	}
+	txs, err := a.l2.GetPoolTxsByAtomicGroupIDAPI(atomicGroupID)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+
+	signed := make(map[babyjub.PublicKeyComp]bool, len(cancellation.Signatures))
+	for _, sig := range cancellation.Signatures {
+		if !sig.verify(atomicGroupID) {
+			retBadReq(ErrAtomicGroupCancelSignatureInvalid, c)
+			return
+		}
+		signed[sig.FromBJJ.Compress()] = true
+	}
+	for _, tx := range txs {
+		if !signed[tx.FromBJJ.Compress()] {
+			retBadReq(ErrAtomicGroupCancelSignatureMissing, c)
+			return
+		}
+	}
+
+	signers := make([]babyjub.PublicKeyComp, 0, len(signed))
+	for signer := range signed {
+		signers = append(signers, signer)
+	}
+	if err := a.l2.CancelAtomicGroup(atomicGroupID, signers); err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// putAtomicGroup handles PUT /atomic-pool/:atomicGroupId: replaces a
+// still-pending atomic group with a new one that addresses the same set
+// of FromIdx, each with a strictly higher Nonce and Fee than the tx it
+// replaces (analogous to replace-by-fee).
+func (a *API) putAtomicGroup(c *gin.Context) {
+	atomicGroupID, err := parsers.ParseParamAtomicGroupID(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	originalTxs, err := a.l2.GetPoolTxsByAtomicGroupIDAPI(atomicGroupID)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+
+	var replacement AtomicGroup
+	if err := c.ShouldBindJSON(&replacement); err != nil {
+		retBadReq(err, c)
+		return
+	}
+	if err := validateAtomicGroupReplacement(originalTxs, replacement.Txs); err != nil {
+		retBadReq(err, c)
+		return
+	}
+	txIDStrings, err := a.prepareAtomicGroupTxs(&replacement, c.ClientIP())
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+
+	if err := a.l2.ReplaceAtomicGroup(atomicGroupID, replacement.Txs); err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, txIDStrings)
+}
+
+// validateAtomicGroupReplacement enforces the replace-by-fee rule:
+// replacement must address exactly the same set of FromIdx as original,
+// each with a strictly greater Nonce and Fee than the tx it replaces.
+func validateAtomicGroupReplacement(original, replacement []common.PoolL2Tx) error {
+	if len(original) != len(replacement) {
+		return ErrAtomicGroupNotReplaceable
+	}
+	byFromIdx := make(map[common.Idx]common.PoolL2Tx, len(original))
+	for _, tx := range original {
+		byFromIdx[tx.FromIdx] = tx
+	}
+	seen := make(map[common.Idx]bool, len(replacement))
+	for _, tx := range replacement {
+		prev, ok := byFromIdx[tx.FromIdx]
+		if !ok || seen[tx.FromIdx] {
+			return ErrAtomicGroupNotReplaceable
+		}
+		seen[tx.FromIdx] = true
+		if tx.Nonce <= prev.Nonce || tx.Fee <= prev.Fee {
+			return ErrAtomicGroupNotReplaceable
+		}
+	}
+	return nil
+}
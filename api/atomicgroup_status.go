@@ -0,0 +1,111 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hermeznetwork/hermez-node/api/parsers"
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// AtomicGroupTxStatus is the per-tx projection returned by
+// getAtomicGroupStatus and carried by every event getAtomicGroupEvents
+// streams
+type AtomicGroupTxStatus struct {
+	TxID     common.TxID          `json:"txID"`
+	State    common.PoolL2TxState `json:"state"`
+	BatchNum *common.BatchNum     `json:"batchNum"`
+}
+
+// AtomicGroupStatus is the response of GET /atomic-pool/:atomicGroupId/status
+type AtomicGroupStatus struct {
+	ID  common.AtomicGroupID  `json:"atomicGroupId"`
+	Txs []AtomicGroupTxStatus `json:"transactions"`
+}
+
+func atomicGroupStatusFromTxs(id common.AtomicGroupID, txs []common.PoolL2Tx) *AtomicGroupStatus {
+	status := &AtomicGroupStatus{ID: id, Txs: make([]AtomicGroupTxStatus, len(txs))}
+	for i, tx := range txs {
+		status.Txs[i] = AtomicGroupTxStatus{TxID: tx.TxID, State: tx.State, BatchNum: tx.BatchNum}
+	}
+	return status
+}
+
+// isTerminalPoolL2TxState reports whether state is one a tx never leaves:
+// once every tx in a group is Forged or Invalid, the group's lifecycle is
+// over and getAtomicGroupEvents closes its stream.
+func isTerminalPoolL2TxState(state common.PoolL2TxState) bool {
+	return state == common.PoolL2TxStateForged || state == common.PoolL2TxStateInvalid
+}
+
+// getAtomicGroupStatus handles GET /atomic-pool/:atomicGroupId/status,
+// a lighter-weight alternative to getAtomicGroup for callers that only
+// need to know each tx's lifecycle state, not its full contents.
+func (a *API) getAtomicGroupStatus(c *gin.Context) {
+	atomicGroupID, err := parsers.ParseParamAtomicGroupID(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	txs, err := a.l2.GetPoolTxsByAtomicGroupIDAPI(atomicGroupID)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, atomicGroupStatusFromTxs(atomicGroupID, txs))
+}
+
+// getAtomicGroupEvents handles GET /atomic-pool/:atomicGroupId/events,
+// streaming a server-sent event per state transition of any tx in the
+// group (pending -> selected -> forged -> confirmed / invalidated) as
+// l2db.AtomicGroupTxEvents arrive from l2db's pub/sub, until every tx in
+// the group has reached a terminal state or the client disconnects.
+func (a *API) getAtomicGroupEvents(c *gin.Context) {
+	atomicGroupID, err := parsers.ParseParamAtomicGroupID(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	txs, err := a.l2.GetPoolTxsByAtomicGroupIDAPI(atomicGroupID)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	states := make(map[common.TxID]common.PoolL2TxState, len(txs))
+	allTerminal := true
+	for _, tx := range txs {
+		states[tx.TxID] = tx.State
+		if !isTerminalPoolL2TxState(tx.State) {
+			allTerminal = false
+		}
+	}
+	// The group already reached its final state: there's nothing left to
+	// stream, so respond once instead of opening an SSE connection.
+	if allTerminal {
+		c.JSON(http.StatusOK, atomicGroupStatusFromTxs(atomicGroupID, txs))
+		return
+	}
+
+	events, unsubscribe := a.l2.SubscribeAtomicGroup(atomicGroupID)
+	defer unsubscribe()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("state", evt)
+			states[evt.TxID] = evt.NewState
+			for _, state := range states {
+				if !isTerminalPoolL2TxState(state) {
+					return true
+				}
+			}
+			return false
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
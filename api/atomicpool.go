@@ -7,7 +7,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/hermeznetwork/hermez-node/api/parsers"
 	"github.com/hermeznetwork/hermez-node/common"
-	"github.com/yourbasic/graph"
+	"github.com/hermeznetwork/hermez-node/common/atomic"
 )
 
 // AtomicGroup represents a set of atomic transactions
@@ -43,130 +43,83 @@ func (a *API) postAtomicPool(c *gin.Context) {
 		retBadReq(err, c)
 		return
 	}
-	// Validate atomic group id
-	if !receivedAtomicGroup.IsAtomicGroupIDValid() {
-		retBadReq(errors.New(ErrInvalidAtomicGroupID), c)
+	txIDStrings, err := a.prepareAtomicGroupTxs(&receivedAtomicGroup, c.ClientIP())
+	if err != nil {
+		retBadReq(err, c)
 		return
 	}
-	nTxs := len(receivedAtomicGroup.Txs)
-	if nTxs <= 1 {
-		retBadReq(errors.New(ErrSingleTxInAtomicEndpoint), c)
+	// Insert to DB
+	if err := a.l2.AddAtomicTxsAPI(receivedAtomicGroup.Txs); err != nil {
+		retSQLErr(err, c)
 		return
 	}
+	// Return IDs of the added txs in the pool
+	c.JSON(http.StatusOK, txIDStrings)
+}
+
+// prepareAtomicGroupTxs validates group (id, RqOffset linkage, per-tx
+// signature/balance checks via verifyPoolL2Tx, and single-atomic-group
+// connectivity), filling in the Rq* fields and ClientIP/AtomicGroupID on
+// every tx in place, and returns their TxIDs in order. It's shared by
+// postAtomicPool and putAtomicGroup, which both submit a full AtomicGroup
+// to be validated and persisted.
+func (a *API) prepareAtomicGroupTxs(group *AtomicGroup, clientIP string) ([]string, error) {
+	// Validate atomic group id
+	if !group.IsAtomicGroupIDValid() {
+		return nil, errors.New(ErrInvalidAtomicGroupID)
+	}
+	nTxs := len(group.Txs)
+	if nTxs <= 1 {
+		return nil, errors.New(ErrSingleTxInAtomicEndpoint)
+	}
 	// Validate txs
 	txIDStrings := make([]string, nTxs) // used for successful response
-	clientIP := c.ClientIP()
-	for i, tx := range receivedAtomicGroup.Txs {
+	for i, tx := range group.Txs {
 		// Find requested transaction
-		relativePosition, err := requestOffset2RelativePosition(tx.RqOffset)
+		relativePosition, err := atomic.RequestOffset2RelativePosition(tx.RqOffset)
 		if err != nil {
-			retBadReq(err, c)
-			return
+			return nil, err
 		}
 		requestedPosition := i + relativePosition
-		if requestedPosition > len(receivedAtomicGroup.Txs)-1 || requestedPosition < 0 {
-			retBadReq(errors.New(ErrRqOffsetOutOfBounds), c)
-			return
+		if requestedPosition > len(group.Txs)-1 || requestedPosition < 0 {
+			return nil, errors.New(ErrRqOffsetOutOfBounds)
 		}
 		// Set fields that are omitted in the JSON
-		requestedTx := receivedAtomicGroup.Txs[requestedPosition]
-		receivedAtomicGroup.Txs[i].RqFromIdx = requestedTx.FromIdx
-		receivedAtomicGroup.Txs[i].RqToIdx = requestedTx.ToIdx
-		receivedAtomicGroup.Txs[i].RqToEthAddr = requestedTx.ToEthAddr
-		receivedAtomicGroup.Txs[i].RqToBJJ = requestedTx.ToBJJ
-		receivedAtomicGroup.Txs[i].RqTokenID = requestedTx.TokenID
-		receivedAtomicGroup.Txs[i].RqAmount = requestedTx.Amount
-		receivedAtomicGroup.Txs[i].RqFee = requestedTx.Fee
-		receivedAtomicGroup.Txs[i].RqNonce = requestedTx.Nonce
-		receivedAtomicGroup.Txs[i].ClientIP = clientIP
-		receivedAtomicGroup.Txs[i].AtomicGroupID = receivedAtomicGroup.ID
+		requestedTx := group.Txs[requestedPosition]
+		group.Txs[i].RqFromIdx = requestedTx.FromIdx
+		group.Txs[i].RqToIdx = requestedTx.ToIdx
+		group.Txs[i].RqToEthAddr = requestedTx.ToEthAddr
+		group.Txs[i].RqToBJJ = requestedTx.ToBJJ
+		group.Txs[i].RqTokenID = requestedTx.TokenID
+		group.Txs[i].RqAmount = requestedTx.Amount
+		group.Txs[i].RqFee = requestedTx.Fee
+		group.Txs[i].RqNonce = requestedTx.Nonce
+		group.Txs[i].ClientIP = clientIP
+		group.Txs[i].AtomicGroupID = group.ID
 
 		// Validate transaction
-		if err := a.verifyPoolL2Tx(receivedAtomicGroup.Txs[i]); err != nil {
-			retBadReq(err, c)
-			return
+		if err := a.verifyPoolL2Tx(group.Txs[i]); err != nil {
+			return nil, err
 		}
 
 		// Prepare response
-		txIDStrings[i] = receivedAtomicGroup.Txs[i].TxID.String()
+		txIDStrings[i] = group.Txs[i].TxID.String()
 	}
 
-	// Validate that all txs in the payload represent a single atomic group
-	if !isSingleAtomicGroup(receivedAtomicGroup.Txs) {
-		retBadReq(errors.New(ErrTxsNotAtomic), c)
-		return
-	}
-	// Insert to DB
-	if err := a.l2.AddAtomicTxsAPI(receivedAtomicGroup.Txs); err != nil {
-		retSQLErr(err, c)
-		return
+	// Validate that all txs in the payload represent a single atomic group,
+	// and compute the canonical order the coordinator will place them in
+	// the batch so it doesn't need to recompute it every selection round.
+	atomicGroup, err := atomic.Analyze(group.Txs)
+	if err != nil {
+		return nil, err
 	}
-	// Return IDs of the added txs in the pool
-	c.JSON(http.StatusOK, txIDStrings)
-}
-
-// requestOffset2RelativePosition translates from 0 to 7 to protocol position
-func requestOffset2RelativePosition(rqoffset uint8) (int, error) {
-	const rqOffsetZero = 0
-	const rqOffsetOne = 1
-	const rqOffsetTwo = 2
-	const rqOffsetThree = 3
-	const rqOffsetFour = 4
-	const rqOffsetFive = 5
-	const rqOffsetSix = 6
-	const rqOffsetSeven = 7
-	const rqOffsetMinusFour = -4
-	const rqOffsetMinusThree = -3
-	const rqOffsetMinusTwo = -2
-	const rqOffsetMinusOne = -1
-
-	switch rqoffset {
-	case rqOffsetZero:
-		return rqOffsetZero, errors.New(ErrTxsNotAtomic)
-	case rqOffsetOne:
-		return rqOffsetOne, nil
-	case rqOffsetTwo:
-		return rqOffsetTwo, nil
-	case rqOffsetThree:
-		return rqOffsetThree, nil
-	case rqOffsetFour:
-		return rqOffsetMinusFour, nil
-	case rqOffsetFive:
-		return rqOffsetMinusThree, nil
-	case rqOffsetSix:
-		return rqOffsetMinusTwo, nil
-	case rqOffsetSeven:
-		return rqOffsetMinusOne, nil
-	default:
-		return rqOffsetZero, errors.New(ErrInvalidRqOffset)
+	if !atomicGroup.IsSingleGroup {
+		return nil, errors.New(ErrTxsNotAtomic)
 	}
-}
-
-// isSingleAtomicGroup returns true if all the txs are needed to be forged
-// (all txs will be forged in the same batch or non of them will be forged)
-func isSingleAtomicGroup(txs []common.PoolL2Tx) bool {
-	// Create a graph from the given txs to represent requests between transactions
-	g := graph.New(len(txs))
-	// Create vertices that connect nodes of the graph (txs) using RqOffset
-	for i, tx := range txs {
-		requestedRelativePosition, err := requestOffset2RelativePosition(tx.RqOffset)
-		if err != nil {
-			return false
-		}
-		requestedPosition := i + requestedRelativePosition
-		if requestedPosition < 0 || requestedPosition >= len(txs) {
-			// Safety check: requested tx is not out of array bounds
-			return false
-		}
-		g.Add(i, requestedPosition)
+	for position, idx := range atomicGroup.Order {
+		group.Txs[idx].AtomicPosition = position
 	}
-	// A graph with a single strongly connected component,
-	// means that all the nodes can be reached from all the nodes.
-	// If tx A "can reach" tx B it means that tx A requests tx B.
-	// Therefore we can say that if there is a single strongly connected component in the graph,
-	// all the transactions require all trnsactions to be forged, in other words: they are an atomic group
-	strongComponents := graph.StrongComponents(g)
-	return len(strongComponents) == 1
+	return txIDStrings, nil
 }
 
 func (a *API) getAtomicGroup(c *gin.Context) {
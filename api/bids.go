@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/hermeznetwork/hermez-node/db"
+)
+
+// getBidAPI is the API projection of a single bid placed on a slot
+type getBidAPI struct {
+	SlotNum  int64             `json:"slotNum"`
+	BidValue string            `json:"bidValue"`
+	Bidder   ethCommon.Address `json:"bidderAddr"`
+	Forger   ethCommon.Address `json:"forgerAddr"`
+}
+
+// getBidsAPI is the response of getBids
+type getBidsAPI struct {
+	Bids       []getBidAPI    `json:"bids"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+func (a *API) getBids(c *gin.Context) {
+	slotNum, err := parseQuerySlotNum("slotNum", c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	var bidderAddr *ethCommon.Address
+	if addrStr := c.Query("bidderAddr"); addrStr != "" {
+		bidderAddr, err = parseQueryHezEthAddr("bidderAddr", c)
+		if err != nil {
+			retBadReq(err, c)
+			return
+		}
+	}
+	fromItem, order, limit, err := parsePagination(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	bids, pagination, err := a.h.GetBidsAPI(slotNum, bidderAddr, fromItem, limit, order)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	apiBids := make([]getBidAPI, len(bids))
+	for i, bid := range bids {
+		apiBids[i] = getBidAPI{
+			SlotNum:  bid.SlotNum,
+			BidValue: bid.BidValue.String(),
+			Bidder:   bid.Bidder,
+			Forger:   bid.Forger,
+		}
+	}
+	c.JSON(http.StatusOK, getBidsAPI{Bids: apiBids, Pagination: pagination})
+}
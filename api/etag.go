@@ -0,0 +1,71 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// etagBodyWriter buffers a handler's response instead of forwarding it
+// straight to the client, so etagMiddleware can hash the full body and
+// decide between a normal response and a 304 before anything is written.
+type etagBodyWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *etagBodyWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *etagBodyWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// etagMiddleware computes a strong ETag from each 200 response's body and
+// honors If-None-Match by answering 304 instead of resending the body.
+// It's meant for deterministic, read-only endpoints whose body is fully
+// determined by their request (getExit, getHistoryTx,
+// getAccountCreationAuth, getConfig, getState), e.g.:
+//
+//	router.GET("/exits/:batchNum/:idx", etagMiddleware(), getExit)
+//
+// Wiring it onto write endpoints would be harmless but pointless, since
+// their responses aren't cacheable.
+func etagMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bw := &etagBodyWriter{ResponseWriter: c.Writer}
+		c.Writer = bw
+		c.Next()
+
+		status := bw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status != http.StatusOK || bw.body.Len() == 0 {
+			bw.ResponseWriter.WriteHeader(status)
+			_, _ = bw.ResponseWriter.Write(bw.body.Bytes())
+			return
+		}
+
+		etag := computeETag(bw.body.Bytes())
+		bw.ResponseWriter.Header().Set("ETag", etag)
+		if c.GetHeader("If-None-Match") == etag {
+			bw.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+		bw.ResponseWriter.WriteHeader(status)
+		_, _ = bw.ResponseWriter.Write(bw.body.Bytes())
+	}
+}
+
+// computeETag returns a strong ETag for body, quoted as required by RFC
+// 7232
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
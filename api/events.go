@@ -0,0 +1,131 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+const (
+	// maxStreamSubscribers caps how many concurrent subscribers a single
+	// eventBus will serve, so a burst of clients can't hold open an
+	// unbounded number of channels/goroutines
+	maxStreamSubscribers = 1024
+
+	// streamBufferSize is how many pending events a subscriber can be
+	// behind before it's considered too slow to keep up
+	streamBufferSize = 64
+)
+
+// poolTxEvent is published by postPoolTx whenever a tx is accepted into
+// the pool
+type poolTxEvent struct {
+	TxID    common.TxID    `json:"txID"`
+	FromIdx common.Idx     `json:"fromIdx"`
+	ToIdx   common.Idx     `json:"toIdx"`
+	TokenID common.TokenID `json:"tokenId"`
+}
+
+// batchEvent is published by apiSyncHandler whenever the synchronizer
+// commits a newly forged batch
+type batchEvent struct {
+	BatchNum    common.BatchNum `json:"batchNum"`
+	EthBlockNum int64           `json:"ethereumBlockNum"`
+	StateRoot   string          `json:"stateRoot"`
+}
+
+// exitEvent is published by apiSyncHandler whenever the synchronizer
+// commits a batch containing a new exit
+type exitEvent struct {
+	BatchNum common.BatchNum `json:"batchNum"`
+	Idx      common.Idx      `json:"accountIndex"`
+	Balance  string          `json:"balance"`
+}
+
+// eventFilter narrows a subscription down to events touching a specific
+// account or token, mirroring the account filters getAccounts/getExits
+// accept. A zero-value eventFilter matches everything.
+type eventFilter struct {
+	tokenID *common.TokenID
+	idx     *common.Idx
+}
+
+func (f eventFilter) matchesIdx(idx common.Idx) bool {
+	return f.idx == nil || *f.idx == idx
+}
+
+func (f eventFilter) matchesTokenID(tokenID common.TokenID) bool {
+	return f.tokenID == nil || *f.tokenID == tokenID
+}
+
+func (f eventFilter) matchesPoolTx(ev poolTxEvent) bool {
+	return f.matchesTokenID(ev.TokenID) && (f.matchesIdx(ev.FromIdx) || f.matchesIdx(ev.ToIdx))
+}
+
+func (f eventFilter) matchesExit(ev exitEvent) bool {
+	return f.matchesIdx(ev.Idx)
+}
+
+// poolTxEvents, batchEvents and exitEvents are the process-wide event
+// buses postPoolTx and apiSyncHandler publish to, and
+// getPoolTxEvents/getBatchEvents/getExitEvents subscribe from.
+var (
+	poolTxEvents = newEventBus()
+	batchEvents  = newEventBus()
+	exitEvents   = newEventBus()
+)
+
+// eventBus fans a stream of events out to every current subscriber.
+// publish never blocks on a slow subscriber: one that falls
+// streamBufferSize events behind is unsubscribed and its channel closed,
+// instead of buffering without bound or stalling every other subscriber.
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[chan interface{}]eventFilter
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[chan interface{}]eventFilter)}
+}
+
+// subscribe registers filter and returns the channel to read events from
+// and an unsubscribe func to release it. ok is false if the bus is
+// already serving maxStreamSubscribers.
+func (b *eventBus) subscribe(filter eventFilter) (ch chan interface{}, unsubscribe func(), ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.subs) >= maxStreamSubscribers {
+		return nil, nil, false
+	}
+	ch = make(chan interface{}, streamBufferSize)
+	b.subs[ch] = filter
+	unsubscribe = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, true
+}
+
+// publish fans event out to every subscriber whose filter matches it, as
+// reported by matches
+func (b *eventBus) publish(event interface{}, matches func(eventFilter) bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch, filter := range b.subs {
+		if !matches(filter) {
+			continue
+		}
+		select {
+		case ch <- event:
+		default:
+			// Slow consumer: drop it rather than block every other
+			// subscriber or grow its backlog forever.
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// recommendedFeeSampleSize is how many of the most recently forged L2 txs
+// getRecommendedFee samples to derive the fee tiers
+const recommendedFeeSampleSize = 500
+
+// recommendedFeeAPI is the response of getRecommendedFee: USD-denominated
+// fee tiers derived from what recently-forged L2 txs actually paid, so
+// clients can pick a fee likely to get them into the next batches without
+// overpaying
+type recommendedFeeAPI struct {
+	// Slow is the 25th percentile fee paid by recently forged txs
+	Slow float64 `json:"feeSlow"`
+	// Average is the median fee paid by recently forged txs
+	Average float64 `json:"feeAverage"`
+	// Fast is the 75th percentile fee paid by recently forged txs
+	Fast float64 `json:"feeFast"`
+}
+
+func (a *API) getRecommendedFee(c *gin.Context) {
+	fees, err := a.h.GetRecentForgedTxFeesUSD(recommendedFeeSampleSize)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	c.JSON(http.StatusOK, recommendedFee(fees))
+}
+
+// recommendedFee computes the slow/average/fast fee tiers out of the USD
+// fees paid by a sample of recently forged txs. An empty sample (e.g. right
+// after a fresh deployment) yields all-zero tiers rather than an error,
+// since a fee of 0 is a safe, if imprecise, recommendation.
+func recommendedFee(feesUSD []float64) recommendedFeeAPI {
+	if len(feesUSD) == 0 {
+		return recommendedFeeAPI{}
+	}
+	sorted := append([]float64{}, feesUSD...)
+	sort.Float64s(sorted)
+	return recommendedFeeAPI{
+		Slow:    percentile(sorted, 0.25),
+		Average: percentile(sorted, 0.5),
+		Fast:    percentile(sorted, 0.75),
+	}
+}
+
+// percentile returns the value at p (0 to 1) of sorted, which must already
+// be sorted ascending and non-empty
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
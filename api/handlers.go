@@ -86,6 +86,16 @@ func postPoolTx(c *gin.Context) {
 		retSQLErr(err, c)
 		return
 	}
+	// Notify subscribers of getPoolTxEvents
+	ev := poolTxEvent{
+		TxID:    writeTx.TxID,
+		FromIdx: writeTx.FromIdx,
+		ToIdx:   writeTx.ToIdx,
+		TokenID: writeTx.TokenID,
+	}
+	poolTxEvents.publish(ev, func(filter eventFilter) bool {
+		return filter.matchesPoolTx(ev)
+	})
 	// Return TxID
 	c.JSON(http.StatusOK, writeTx.TxID.String())
 }
@@ -108,14 +118,6 @@ func getPoolTx(c *gin.Context) {
 	c.JSON(http.StatusOK, apiTx)
 }
 
-func getAccounts(c *gin.Context) {
-
-}
-
-func getAccount(c *gin.Context) {
-
-}
-
 func getExits(c *gin.Context) {
 	// Get query parameters
 	// Account filters
@@ -195,30 +197,10 @@ func getHistoryTx(c *gin.Context) {
 	c.JSON(http.StatusOK, apiTxs[0])
 }
 
-func getSlots(c *gin.Context) {
-
-}
-
-func getBids(c *gin.Context) {
-
-}
-
-func getNextForgers(c *gin.Context) {
-
-}
-
-func getState(c *gin.Context) {
-
-}
-
 func getConfig(c *gin.Context) {
 	c.JSON(http.StatusOK, cg)
 }
 
-func getRecommendedFee(c *gin.Context) {
-
-}
-
 func retSQLErr(err error, c *gin.Context) {
 	if err == sql.ErrNoRows {
 		c.JSON(http.StatusNotFound, errorMsg{
@@ -5,9 +5,22 @@ import (
 	"time"
 
 	"github.com/dimiro1/health"
+	"github.com/hermeznetwork/hermez-node/common"
 	"github.com/hermeznetwork/hermez-node/health/checkers"
 )
 
+// maxSyncLagBlocks is the number of ethereum blocks the synchronizer is
+// allowed to fall behind before /health reports it as degraded
+const maxSyncLagBlocks = 20
+
+// maxPoolBacklog and maxPoolTxAge bound how large the pending/forging
+// backlog of the L2 pool is allowed to grow before /health reports it as
+// degraded
+const (
+	maxPoolBacklog = 10000
+	maxPoolTxAge   = 10 * time.Minute
+)
+
 func (a *API) healthRoute(version string) http.Handler {
 	// taking two checkers for one db in case that in
 	// the future there will be two separated dbs
@@ -16,8 +29,36 @@ func (a *API) healthRoute(version string) http.Handler {
 	healthHandler := health.NewHandler()
 	healthHandler.AddChecker("l2DB", l2DBChecker)
 	healthHandler.AddChecker("historyDB", historyDBChecker)
+
+	if a.s != nil {
+		healthHandler.AddChecker("synchronizerStateDB",
+			checkers.NewStateDBChecker(a.s.StateDB))
+		healthHandler.AddChecker("synchronizerLag",
+			checkers.NewSyncLagChecker(a.syncCheckerStats, maxSyncLagBlocks))
+	}
+	healthHandler.AddChecker("pool", checkers.NewPoolChecker(a.l2,
+		[]common.PoolL2TxState{
+			common.PoolL2TxStatePending,
+			common.PoolL2TxStateForging,
+		}, maxPoolBacklog, maxPoolTxAge))
+	healthHandler.AddChecker("atomicGroups",
+		checkers.NewAtomicGroupChecker(a.l2.CountActiveAtomicGroupsAPI))
+
 	healthHandler.AddInfo("version", version)
 	t := time.Now().UTC()
 	healthHandler.AddInfo("timestamp", t)
 	return healthHandler
 }
+
+// syncCheckerStats adapts the synchronizer's own Stats to the narrow shape
+// checkers.NewSyncLagChecker reads, so the checkers package doesn't need to
+// import synchronizer
+func (a *API) syncCheckerStats() *checkers.Stats {
+	stats := a.s.Stats()
+	return &checkers.Stats{
+		EthLastBlock:  stats.Eth.LastBlock.Num,
+		SyncLastBlock: stats.Sync.LastBlock.Num,
+		EthLastBatch:  stats.Eth.LastBatch,
+		SyncLastBatch: stats.Sync.LastBatch,
+	}
+}
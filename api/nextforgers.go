@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// nextForgersWindow is how many upcoming slots, starting at the current
+// one, getNextForgers reports on
+const nextForgersWindow = 6
+
+// nextForgerAPI describes who is entitled to forge a given upcoming slot
+type nextForgerAPI struct {
+	SlotNum    int64   `json:"slotNum"`
+	FirstBlock int64   `json:"firstBlock"`
+	LastBlock  int64   `json:"lastBlock"`
+	WinnerBid  *bidAPI `json:"winnerBid"`
+}
+
+func (a *API) getNextForgers(c *gin.Context) {
+	lastBlock, err := a.h.GetLastBlockAPI()
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	auctionVars, err := a.h.GetAuctionVarsAPI()
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	currentSlot := a.getCurrentSlot(lastBlock.EthBlockNum)
+	nextForgers := make([]nextForgerAPI, 0, nextForgersWindow)
+	for slotNum := currentSlot; slotNum < currentSlot+nextForgersWindow; slotNum++ {
+		winnerBid, err := a.resolveSlotWinner(slotNum, auctionVars)
+		if err != nil {
+			retSQLErr(err, c)
+			return
+		}
+		firstBlock, lastBlockOfSlot := a.getFirstLastBlock(slotNum)
+		nextForgers = append(nextForgers, nextForgerAPI{
+			SlotNum:    slotNum,
+			FirstBlock: firstBlock,
+			LastBlock:  lastBlockOfSlot,
+			WinnerBid:  winnerBid,
+		})
+	}
+	c.JSON(http.StatusOK, nextForgers)
+}
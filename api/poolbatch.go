@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// maxPoolTxsBatchSize caps how many txs postPoolTxsBatch accepts in a
+// single request, so one wallet can't tie up the pool insert with an
+// unbounded array
+const maxPoolTxsBatchSize = 100
+
+var (
+	// errEmptyPoolTxsBatch is returned when postPoolTxsBatch is called
+	// with an empty array
+	errEmptyPoolTxsBatch = errors.New("transactions array can't be empty")
+	// errPoolTxsBatchTooLarge is returned when postPoolTxsBatch is called
+	// with more than maxPoolTxsBatchSize txs
+	errPoolTxsBatchTooLarge = fmt.Errorf("transactions array can't hold more than %d txs", maxPoolTxsBatchSize)
+)
+
+// poolTxsBatchItemAPI is the per-item result of postPoolTxsBatch: TxID is
+// set if the tx was accepted, Error carries why it wasn't otherwise
+type poolTxsBatchItemAPI struct {
+	TxID  *common.TxID `json:"txID,omitempty"`
+	Error string       `json:"error,omitempty"`
+}
+
+// nonceConflict reports the first (FromIdx, Nonce) pair shared by more
+// than one tx in txs, so postPoolTxsBatch can reject the whole batch
+// rather than guess which of the conflicting txs the sender meant
+func nonceConflict(txs []common.PoolL2Tx) (common.Idx, common.Nonce, bool) {
+	type key struct {
+		idx   common.Idx
+		nonce common.Nonce
+	}
+	seen := make(map[key]bool, len(txs))
+	for _, tx := range txs {
+		k := key{idx: tx.FromIdx, nonce: tx.Nonce}
+		if seen[k] {
+			return tx.FromIdx, tx.Nonce, true
+		}
+		seen[k] = true
+	}
+	return 0, 0, false
+}
+
+// postPoolTxsBatch handles POST /transactions-pool/batch: it accepts an
+// array of receivedPoolTx, sharing postPoolTx's per-tx signature
+// verification, and inserts every tx that passes validation in a single
+// l2DB SQL transaction so the batch is applied atomically. The whole
+// batch is rejected up front if any two txs collide on (FromIdx, Nonce),
+// since the pool can't accept two different txs at the same nonce for
+// the same account; any other per-tx validation failure is reported in
+// that tx's own result instead of failing the rest of the batch.
+func postPoolTxsBatch(c *gin.Context) {
+	var received []receivedPoolTx
+	if err := c.ShouldBindJSON(&received); err != nil {
+		retBadReq(err, c)
+		return
+	}
+	if len(received) == 0 {
+		retBadReq(errEmptyPoolTxsBatch, c)
+		return
+	}
+	if len(received) > maxPoolTxsBatchSize {
+		retBadReq(errPoolTxsBatchTooLarge, c)
+		return
+	}
+
+	results := make([]poolTxsBatchItemAPI, len(received))
+	writeTxs := make([]common.PoolL2Tx, 0, len(received))
+	writeTxPositions := make([]int, 0, len(received))
+	for i, receivedTx := range received {
+		writeTx, err := receivedTx.toDBWritePoolL2Tx()
+		if err != nil {
+			results[i] = poolTxsBatchItemAPI{Error: err.Error()}
+			continue
+		}
+		writeTxs = append(writeTxs, writeTx)
+		writeTxPositions = append(writeTxPositions, i)
+	}
+
+	if idx, nonce, conflict := nonceConflict(writeTxs); conflict {
+		retBadReq(fmt.Errorf("more than one tx for account %v at nonce %v", idx, nonce), c)
+		return
+	}
+
+	if len(writeTxs) > 0 {
+		if err := l2.AddTxs(writeTxs); err != nil {
+			retSQLErr(err, c)
+			return
+		}
+		for i, pos := range writeTxPositions {
+			txID := writeTxs[i].TxID
+			results[pos] = poolTxsBatchItemAPI{TxID: &txID}
+			ev := poolTxEvent{
+				TxID:    writeTxs[i].TxID,
+				FromIdx: writeTxs[i].FromIdx,
+				ToIdx:   writeTxs[i].ToIdx,
+				TokenID: writeTxs[i].TokenID,
+			}
+			poolTxEvents.publish(ev, func(filter eventFilter) bool {
+				return filter.matchesPoolTx(ev)
+			})
+		}
+	}
+	c.JSON(http.StatusOK, results)
+}
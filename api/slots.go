@@ -0,0 +1,264 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/historydb"
+	"github.com/hermeznetwork/tracerr"
+)
+
+var (
+	// errParamSlotNum is returned when a slotNum path/query param isn't a
+	// valid non-negative integer
+	errParamSlotNum = errors.New("invalid slotNum")
+	// errParamFinishedAuction is returned when the finishedAuction query
+	// param isn't a valid bool
+	errParamFinishedAuction = errors.New("invalid finishedAuction")
+	// errSlotsNeedBounds is returned when getSlots is called with neither
+	// minSlotNum nor maxSlotNum set
+	errSlotsNeedBounds = errors.New("minSlotNum or maxSlotNum is required")
+	// errInvalidSlotNumRange is returned when minSlotNum > maxSlotNum
+	errInvalidSlotNumRange = errors.New("minSlotNum can't be greater than maxSlotNum")
+	// errSlotNumRangeTooWide is returned when maxSlotNum - minSlotNum
+	// exceeds maxSlotRange
+	errSlotNumRangeTooWide = fmt.Errorf("slotNum range can't span more than %d slots", maxSlotRange)
+)
+
+// maxSlotRange caps how many slots getSlots will resolve in a single
+// request: each slot costs a GetBestBidCoordinator round-trip, and an
+// unbounded range can also overflow the to-from+1 length passed to make()
+const maxSlotRange = 2048
+
+// bidAPI is the API projection of the winning bid of a slot, resolved the
+// same way the synchronizer resolves the current slot's coordinator: the
+// highest bidder if it cleared the default slot bid, the boot coordinator
+// otherwise
+type bidAPI struct {
+	Bidder    ethCommon.Address `json:"bidderAddr"`
+	Forger    ethCommon.Address `json:"forgerAddr"`
+	URL       string            `json:"URL"`
+	BidValue  string            `json:"bidValue"`
+	BootCoord bool              `json:"bootCoordinator"`
+}
+
+// slotAPI is the response of getSlot, and the per-item shape of getSlots
+type slotAPI struct {
+	ItemID      uint64  `json:"itemId"`
+	SlotNum     int64   `json:"slotNum"`
+	FirstBlock  int64   `json:"firstBlock"`
+	LastBlock   int64   `json:"lastBlock"`
+	OpenAuction bool    `json:"openAuction"`
+	WinnerBid   *bidAPI `json:"winnerBid"`
+}
+
+// slotsAPI is the response of getSlots
+type slotsAPI struct {
+	Slots        []slotAPI `json:"slots"`
+	PendingItems uint64    `json:"pendingItems"`
+}
+
+// getCurrentSlot returns the slot that lastBlockNum belongs to
+func (a *API) getCurrentSlot(lastBlockNum int64) int64 {
+	return a.consts.Auction.SlotNum(lastBlockNum)
+}
+
+// getFirstLastBlock returns the first and last ethereum block of slotNum
+func (a *API) getFirstLastBlock(slotNum int64) (int64, int64) {
+	return a.consts.Auction.SlotBlocks(slotNum)
+}
+
+// isOpenAuction reports whether the auction for slotNum, as of lastBlockNum,
+// hasn't yet reached auctionVars.ClosedAuctionSlots
+func (a *API) isOpenAuction(lastBlockNum int64, slotNum int64, auctionVars common.AuctionVariables) bool {
+	currentSlot := a.getCurrentSlot(lastBlockNum)
+	return slotNum > currentSlot+int64(auctionVars.ClosedAuctionSlots)
+}
+
+// resolveSlotWinner resolves the winning bidder of slotNum the same way the
+// synchronizer resolves the current slot's coordinator: the highest bidder
+// wins if its bid cleared the default slot bid for that slot, otherwise the
+// boot coordinator is the winner. A slot nobody bid on yet also resolves to
+// the boot coordinator (BootCoord set); the returned *bidAPI is never nil
+// when err is nil.
+func (a *API) resolveSlotWinner(slotNum int64, auctionVars *common.AuctionVariables) (*bidAPI, error) {
+	bidCoord, err := a.h.GetBestBidCoordinator(slotNum)
+	if tracerr.Unwrap(err) == sql.ErrNoRows {
+		return &bidAPI{
+			Forger:    auctionVars.BootCoordinator,
+			URL:       auctionVars.BootCoordinatorURL,
+			BootCoord: true,
+		}, nil
+	} else if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	defaultSlotBid := bidCoord.DefaultSlotSetBid[slotNum%int64(len(bidCoord.DefaultSlotSetBid))]
+	if bidCoord.BidValue.Cmp(defaultSlotBid) >= 0 {
+		return &bidAPI{
+			Bidder:   bidCoord.Bidder,
+			Forger:   bidCoord.Forger,
+			URL:      bidCoord.URL,
+			BidValue: bidCoord.BidValue.String(),
+		}, nil
+	}
+	return &bidAPI{
+		Forger:    auctionVars.BootCoordinator,
+		URL:       auctionVars.BootCoordinatorURL,
+		BootCoord: true,
+	}, nil
+}
+
+func (a *API) getSlot(c *gin.Context) {
+	slotNum, err := strconv.ParseInt(c.Param("slotNum"), 10, 64)
+	if err != nil || slotNum < 0 {
+		retBadReq(errParamSlotNum, c)
+		return
+	}
+	lastBlock, err := a.h.GetLastBlockAPI()
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	auctionVars, err := a.h.GetAuctionVarsAPI()
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	winnerBid, err := a.resolveSlotWinner(slotNum, auctionVars)
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	firstBlock, lastBlockOfSlot := a.getFirstLastBlock(slotNum)
+	c.JSON(http.StatusOK, slotAPI{
+		SlotNum:     slotNum,
+		FirstBlock:  firstBlock,
+		LastBlock:   lastBlockOfSlot,
+		OpenAuction: a.isOpenAuction(lastBlock.EthBlockNum, slotNum, *auctionVars),
+		WinnerBid:   winnerBid,
+	})
+}
+
+func (a *API) getSlots(c *gin.Context) {
+	minSlotNum, err := parseQuerySlotNum("minSlotNum", c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	maxSlotNum, err := parseQuerySlotNum("maxSlotNum", c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	if minSlotNum == nil && maxSlotNum == nil {
+		retBadReq(errSlotsNeedBounds, c)
+		return
+	}
+	if minSlotNum != nil && maxSlotNum != nil && *minSlotNum > *maxSlotNum {
+		retBadReq(errInvalidSlotNumRange, c)
+		return
+	}
+	var bidderAddr *ethCommon.Address
+	if addrStr := c.Query("wonByEthereumAddress"); addrStr != "" {
+		bidderAddr, err = parseQueryHezEthAddr("wonByEthereumAddress", c)
+		if err != nil {
+			retBadReq(err, c)
+			return
+		}
+	}
+
+	lastBlock, err := a.h.GetLastBlockAPI()
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	auctionVars, err := a.h.GetAuctionVarsAPI()
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+
+	from := int64(0)
+	if minSlotNum != nil {
+		from = *minSlotNum
+	}
+	to := a.getCurrentSlot(lastBlock.EthBlockNum)
+	if maxSlotNum != nil {
+		to = *maxSlotNum
+	}
+	if finishedStr := c.Query("finishedAuction"); finishedStr != "" {
+		finished, err := strconv.ParseBool(finishedStr)
+		if err != nil {
+			retBadReq(errParamFinishedAuction, c)
+			return
+		}
+		if finished {
+			to = a.getCurrentSlot(lastBlock.EthBlockNum) + int64(auctionVars.ClosedAuctionSlots)
+		}
+	}
+
+	if to-from > maxSlotRange {
+		retBadReq(errSlotNumRangeTooWide, c)
+		return
+	}
+
+	slots := make([]slotAPI, 0, to-from+1)
+	for slotNum := from; slotNum <= to; slotNum++ {
+		winnerBid, err := a.resolveSlotWinner(slotNum, auctionVars)
+		if err != nil {
+			retSQLErr(err, c)
+			return
+		}
+		if bidderAddr != nil && (winnerBid.BootCoord || winnerBid.Bidder != *bidderAddr) {
+			continue
+		}
+		firstBlock, lastBlockOfSlot := a.getFirstLastBlock(slotNum)
+		slots = append(slots, slotAPI{
+			SlotNum:     slotNum,
+			FirstBlock:  firstBlock,
+			LastBlock:   lastBlockOfSlot,
+			OpenAuction: a.isOpenAuction(lastBlock.EthBlockNum, slotNum, *auctionVars),
+			WinnerBid:   winnerBid,
+		})
+	}
+	if bidderAddr != nil && len(slots) == 0 {
+		retSQLErr(sql.ErrNoRows, c)
+		return
+	}
+	if _, order, _, err := parsePagination(c); err == nil && order == historydb.OrderDesc {
+		for i, j := 0, len(slots)-1; i < j; i, j = i+1, j-1 {
+			slots[i], slots[j] = slots[j], slots[i]
+		}
+	}
+	c.JSON(http.StatusOK, slotsAPI{Slots: slots, PendingItems: 0})
+}
+
+// parseQuerySlotNum parses an optional non-negative int64 query param
+func parseQuerySlotNum(name string, c *gin.Context) (*int64, error) {
+	str := c.Query(name)
+	if str == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || n < 0 {
+		return nil, errParamSlotNum
+	}
+	return &n, nil
+}
+
+// parseQueryHezEthAddr parses the hez-prefixed ethereum address query param
+// named name, the query-string counterpart of parseParamHezEthAddr
+func parseQueryHezEthAddr(name string, c *gin.Context) (*ethCommon.Address, error) {
+	addrStr := c.Query(name)
+	addr, err := common.HezStrToEthAddr(addrStr)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	return addr, nil
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// networkStateAPI is the response of getState: a snapshot of where the
+// node's view of the network currently stands
+type networkStateAPI struct {
+	LastSyncBlock int64 `json:"lastSyncBlock"`
+	LastBatch     int64 `json:"lastBatch"`
+	CurrentSlot   int64 `json:"currentSlot"`
+	Synced        bool  `json:"synced"`
+}
+
+// getState handles GET /state. Its response is cacheable with
+// etagMiddleware; ideally that would hash nothing heavier than
+// lastBatch.BatchNum (ETag generation shouldn't require re-serializing
+// the whole body), but that needs a historyDB.GetLastBatchNum-style
+// accessor this checkout's historyDB doesn't expose, so getState relies
+// on etagMiddleware's generic full-body hash like every other endpoint.
+func (a *API) getState(c *gin.Context) {
+	lastBlock, err := a.h.GetLastBlockAPI()
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	lastBatch, err := a.h.GetLastBatchAPI()
+	if err != nil {
+		retSQLErr(err, c)
+		return
+	}
+	state := networkStateAPI{
+		LastSyncBlock: lastBlock.EthBlockNum,
+		LastBatch:     lastBatch.BatchNum,
+		CurrentSlot:   a.getCurrentSlot(lastBlock.EthBlockNum),
+	}
+	if a.s != nil {
+		stats := a.s.Stats()
+		state.Synced = stats.Synced()
+	}
+	c.JSON(http.StatusOK, state)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// parseEventFilter reads the optional tokenId/accountIndex query params
+// shared by the event-stream endpoints into an eventFilter
+func parseEventFilter(c *gin.Context) (eventFilter, error) {
+	var filter eventFilter
+	tokenID, err := parseQueryUint("tokenId", nil, 0, maxUint32, c)
+	if err != nil {
+		return filter, err
+	}
+	if tokenID != nil {
+		id := common.TokenID(*tokenID)
+		filter.tokenID = &id
+	}
+	idx, err := parseQueryUint("accountIndex", nil, 0, maxUint32, c)
+	if err != nil {
+		return filter, err
+	}
+	if idx != nil {
+		i := common.Idx(*idx)
+		filter.idx = &i
+	}
+	return filter, nil
+}
+
+// streamEvents subscribes to bus with filter and forwards every matching
+// event to c as a server-sent event named name, until the client
+// disconnects or the subscriber is dropped for falling too far behind.
+// bus.publish is what actually applies filter, since filtering happens
+// against the subscriber's stored filter at publish time.
+func streamEvents(c *gin.Context, bus *eventBus, filter eventFilter, name string) {
+	events, unsubscribe, ok := bus.subscribe(filter)
+	if !ok {
+		c.JSON(503, errorMsg{Message: "too many subscribers, try again later"})
+		return
+	}
+	defer unsubscribe()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(name, evt)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// getPoolTxEvents handles GET /events/pool-txs, streaming a server-sent
+// event for every tx accepted into the pool since the client connected,
+// optionally narrowed to a single tokenId and/or accountIndex.
+func (a *API) getPoolTxEvents(c *gin.Context) {
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	streamEvents(c, poolTxEvents, filter, "poolTx")
+}
+
+// getBatchEvents handles GET /events/batches, streaming a server-sent
+// event for every batch the synchronizer forges
+func (a *API) getBatchEvents(c *gin.Context) {
+	streamEvents(c, batchEvents, eventFilter{}, "batch")
+}
+
+// getExitEvents handles GET /events/exits, streaming a server-sent event
+// for every exit the synchronizer commits, optionally narrowed to a
+// single accountIndex. A tokenId param is parsed but doesn't narrow the
+// stream: exitEvent carries no TokenID, so exits of every token match.
+func (a *API) getExitEvents(c *gin.Context) {
+	filter, err := parseEventFilter(c)
+	if err != nil {
+		retBadReq(err, c)
+		return
+	}
+	streamEvents(c, exitEvents, filter, "exit")
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// apiSyncHandler is a synchronizer.SyncEventHandler that republishes
+// forged batches and their exits onto the API's event streams
+// (batchEvents/exitEvents), so getBatchEvents/getExitEvents subscribers
+// learn about them as soon as they're durably committed, without
+// polling historyDB.
+type apiSyncHandler struct{}
+
+// newAPISyncHandler returns a synchronizer.SyncEventHandler to be passed
+// to (*synchronizer.Synchronizer).RegisterHandler when the API is wired
+// up to a live synchronizer
+func newAPISyncHandler() *apiSyncHandler {
+	return &apiSyncHandler{}
+}
+
+// OnBatch publishes a batchEvent for batchData, plus one exitEvent per
+// exit it contains
+func (h *apiSyncHandler) OnBatch(batchData *common.BatchData) {
+	stateRoot := ""
+	if batchData.Batch.StateRoot != nil {
+		stateRoot = batchData.Batch.StateRoot.String()
+	}
+	ev := batchEvent{
+		BatchNum:    batchData.Batch.BatchNum,
+		EthBlockNum: batchData.Batch.EthBlockNum,
+		StateRoot:   stateRoot,
+	}
+	batchEvents.publish(ev, func(eventFilter) bool { return true })
+
+	for _, exit := range batchData.ExitTree {
+		balance := ""
+		if exit.Balance != nil {
+			balance = exit.Balance.String()
+		}
+		ev := exitEvent{
+			BatchNum: exit.BatchNum,
+			Idx:      exit.AccountIdx,
+			Balance:  balance,
+		}
+		exitEvents.publish(ev, func(filter eventFilter) bool {
+			return filter.matchesExit(ev)
+		})
+	}
+}
+
+// OnBlock, OnReorg and OnSlotChange have nothing to republish on the
+// API's event streams
+func (h *apiSyncHandler) OnBlock(blockData *common.BlockData) {}
+func (h *apiSyncHandler) OnReorg(from, to int64)              {}
+func (h *apiSyncHandler) OnSlotChange(slot common.Slot)       {}
@@ -0,0 +1,68 @@
+/*
+Command auditlog inspects a TxSelector's audit log, the pebble-backed store
+of AuditRecord entries written by TxSelector.GetL1L2TxSelection, and prints
+a human-readable report for coordinator operators debugging why a
+profitable pool tx failed to be batched.
+
+Usage:
+
+	auditlog -dbpath /path/to/txselector -batchNum 1234
+	auditlog -dbpath /path/to/txselector -all
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/txselector"
+	"github.com/iden3/go-merkletree/db/pebble"
+)
+
+func main() {
+	dbpath := flag.String("dbpath", "", "path the TxSelector was created with (required)")
+	batchNum := flag.Uint64("batchNum", 0, "report a single batch's AuditRecord")
+	all := flag.Bool("all", false, "report every AuditRecord in the audit log, oldest first")
+	flag.Parse()
+
+	if *dbpath == "" || (*batchNum == 0 && !*all) {
+		fmt.Fprintln(os.Stderr, "usage: auditlog -dbpath PATH {-batchNum N | -all}")
+		os.Exit(2) //nolint:gomnd
+	}
+
+	auditLogDB, err := pebble.NewPebbleStorage(*dbpath+txselector.PathAuditLogDB, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "auditlog:", err)
+		os.Exit(1)
+	}
+
+	if *all {
+		err = auditLogDB.Iterate(func(_, v []byte) (bool, error) {
+			record, unmarshalErr := txselector.UnmarshalAuditRecord(v)
+			if unmarshalErr != nil {
+				return false, unmarshalErr
+			}
+			fmt.Print(txselector.InspectReport(record))
+			return true, nil
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "auditlog:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	b, err := auditLogDB.Get(txselector.AuditLogKey(common.BatchNum(*batchNum)))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "auditlog: no AuditRecord for batch", *batchNum, ":", err)
+		os.Exit(1)
+	}
+	record, err := txselector.UnmarshalAuditRecord(b)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "auditlog:", err)
+		os.Exit(1)
+	}
+	fmt.Print(txselector.InspectReport(record))
+}
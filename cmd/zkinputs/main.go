@@ -0,0 +1,108 @@
+/*
+Command zkinputs converts ZKInputs fixtures between the JSON format
+produced by json.Marshal(common.ZKInputs) and the compact binary format
+from common.ZKInputs.WriteBinary, so the coordinator and the prover can
+exchange the large, mostly-numeric ZKInputs payload without paying JSON
+parsing and decimal-string overhead on either side.
+
+Usage:
+
+	hermez-node zkinputs convert -from json -to binary \
+	    -nLevels 32 -maxTx 376 -maxL1Tx 128 -maxFeeTx 64 \
+	    -in zkinputs.json -out zkinputs.bin
+
+	hermez-node zkinputs convert -from binary -to json \
+	    -in zkinputs.bin -out zkinputs.json
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "convert" {
+		fmt.Fprintln(os.Stderr, "usage: zkinputs convert -from {json|binary} -to {json|binary} -in FILE -out FILE")
+		os.Exit(2) //nolint:gomnd
+	}
+
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	from := fs.String("from", "json", "input format: json or binary")
+	to := fs.String("to", "binary", "output format: json or binary")
+	in := fs.String("in", "", "input file (required)")
+	out := fs.String("out", "", "output file (required)")
+	nLevels := fs.Uint("nLevels", 0, "nLevels, required when -from json and -to binary")
+	maxTx := fs.Uint("maxTx", 0, "maxTx, required when -from json and -to binary")
+	maxL1Tx := fs.Uint("maxL1Tx", 0, "maxL1Tx, required when -from json and -to binary")
+	maxFeeTx := fs.Uint("maxFeeTx", 0, "maxFeeTx, required when -from json and -to binary")
+	if err := fs.Parse(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2) //nolint:gomnd
+	}
+	if *in == "" || *out == "" {
+		fmt.Fprintln(os.Stderr, "-in and -out are required")
+		os.Exit(2) //nolint:gomnd
+	}
+
+	if err := convert(*from, *to, *in, *out, common.ZKInputsDims{
+		NLevels:  uint32(*nLevels),
+		MaxTx:    uint32(*maxTx),
+		MaxL1Tx:  uint32(*maxL1Tx),
+		MaxFeeTx: uint32(*maxFeeTx),
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "zkinputs convert:", err)
+		os.Exit(1)
+	}
+}
+
+func convert(from, to, inPath, outPath string, dims common.ZKInputsDims) error {
+	zki, readDims, err := readZKInputs(from, inPath, dims)
+	if err != nil {
+		return err
+	}
+	if readDims != (common.ZKInputsDims{}) {
+		dims = readDims
+	}
+
+	outFile, err := os.Create(outPath) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	defer outFile.Close() //nolint:errcheck
+
+	switch to {
+	case "json":
+		return zki.WriteJSON(outFile)
+	case "binary":
+		return zki.WriteBinary(outFile, dims)
+	default:
+		return fmt.Errorf("unknown -to format %q", to)
+	}
+}
+
+func readZKInputs(from, inPath string, dims common.ZKInputsDims) (*common.ZKInputs, common.ZKInputsDims, error) {
+	inFile, err := os.Open(inPath) //nolint:gosec
+	if err != nil {
+		return nil, common.ZKInputsDims{}, err
+	}
+	defer inFile.Close() //nolint:errcheck
+
+	switch from {
+	case "json":
+		var zki common.ZKInputs
+		if err := json.NewDecoder(inFile).Decode(&zki); err != nil {
+			return nil, common.ZKInputsDims{}, err
+		}
+		return &zki, dims, nil
+	case "binary":
+		zki, readDims, err := common.ReadBinary(inFile)
+		return zki, readDims, err
+	default:
+		return nil, common.ZKInputsDims{}, fmt.Errorf("unknown -from format %q", from)
+	}
+}
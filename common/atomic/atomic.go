@@ -0,0 +1,120 @@
+// Package atomic validates and canonically orders Hermez atomic tx
+// groups: sets of PoolL2Tx linked together via RqOffset so that either
+// all of them are forged in the same batch, or none are.
+package atomic
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/yourbasic/graph"
+)
+
+// ErrInvalidRqOffset is returned when a tx's RqOffset is not a valid
+// protocol value (1 to 7)
+var ErrInvalidRqOffset = errors.New("invalid RqOffset")
+
+// ErrRqOffsetOutOfBounds is returned when a tx's RqOffset points to a
+// position outside the bounds of the group it's part of
+var ErrRqOffsetOutOfBounds = errors.New("RqOffset points outside the atomic group")
+
+// RequestOffset2RelativePosition translates an RqOffset (1 to 7) into the
+// position of the requested tx relative to the requester, as used to
+// link txs within an atomic group.
+func RequestOffset2RelativePosition(rqoffset uint8) (int, error) {
+	const rqOffsetZero = 0
+	const rqOffsetOne = 1
+	const rqOffsetTwo = 2
+	const rqOffsetThree = 3
+	const rqOffsetFour = 4
+	const rqOffsetFive = 5
+	const rqOffsetSix = 6
+	const rqOffsetSeven = 7
+	const rqOffsetMinusFour = -4
+	const rqOffsetMinusThree = -3
+	const rqOffsetMinusTwo = -2
+	const rqOffsetMinusOne = -1
+
+	switch rqoffset {
+	case rqOffsetZero:
+		return rqOffsetZero, ErrInvalidRqOffset
+	case rqOffsetOne:
+		return rqOffsetOne, nil
+	case rqOffsetTwo:
+		return rqOffsetTwo, nil
+	case rqOffsetThree:
+		return rqOffsetThree, nil
+	case rqOffsetFour:
+		return rqOffsetMinusFour, nil
+	case rqOffsetFive:
+		return rqOffsetMinusThree, nil
+	case rqOffsetSix:
+		return rqOffsetMinusTwo, nil
+	case rqOffsetSeven:
+		return rqOffsetMinusOne, nil
+	default:
+		return rqOffsetZero, ErrInvalidRqOffset
+	}
+}
+
+// Group is the result of analyzing a candidate atomic group.
+type Group struct {
+	// IsSingleGroup is true iff txs form a single strongly connected
+	// component under their RqOffset links, i.e. every tx is reachable
+	// from every other tx, so all of them must be forged together.
+	IsSingleGroup bool
+	// Order holds, when IsSingleGroup is true, a permutation of indices
+	// into the txs slice passed to Analyze: Order[0] is the first tx to
+	// place in the batch, Order[1] the second, and so on. It's the
+	// canonical linearization coordinators and clients should agree on
+	// without recomputing it.
+	Order []int
+}
+
+// Analyze validates that txs form a single atomic group (the same
+// connectivity check previously known as isSingleAtomicGroup) and, if
+// so, computes their canonical linearization.
+//
+// Each tx requests exactly one other tx via RqOffset, so the RqOffset
+// links form a graph with exactly one outgoing edge per vertex. When
+// graph.StrongComponents (Tarjan's algorithm) finds that graph to be a
+// single strongly connected component, it is therefore exactly one cycle
+// that visits every tx. Analyze walks that cycle starting from the tx
+// with the lexicographically smallest TxID - the tie-break that makes
+// the linearization deterministic regardless of submission order.
+func Analyze(txs []common.PoolL2Tx) (*Group, error) {
+	n := len(txs)
+	next := make([]int, n)
+	g := graph.New(n)
+	for i, tx := range txs {
+		relativePosition, err := RequestOffset2RelativePosition(tx.RqOffset)
+		if err != nil {
+			return nil, err
+		}
+		requestedPosition := i + relativePosition
+		if requestedPosition < 0 || requestedPosition >= n {
+			return nil, ErrRqOffsetOutOfBounds
+		}
+		next[i] = requestedPosition
+		g.Add(i, requestedPosition)
+	}
+
+	if len(graph.StrongComponents(g)) != 1 {
+		return &Group{IsSingleGroup: false}, nil
+	}
+
+	start := 0
+	for i := 1; i < n; i++ {
+		if bytes.Compare(txs[i].TxID[:], txs[start].TxID[:]) < 0 {
+			start = i
+		}
+	}
+	order := make([]int, n)
+	cur := start
+	for i := 0; i < n; i++ {
+		order[i] = cur
+		cur = next[cur]
+	}
+	return &Group{IsSingleGroup: true, Order: order}, nil
+}
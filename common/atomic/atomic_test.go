@@ -0,0 +1,138 @@
+package atomic
+
+import (
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// cycleFixtures holds, for each group size from 2 to 8, a single-cycle
+// "next" permutation (next[i] is the index the tx at i requests) whose
+// every step is a valid RqOffset (i.e. within [-4, 3] and non-zero), and
+// the resulting canonical order Analyze is expected to produce when
+// starting from index 0 (whose TxID is made the lexicographically
+// smallest by buildGroup below).
+var cycleFixtures = map[int][]int{
+	2: {1, 0},
+	3: {1, 2, 0},
+	4: {3, 0, 1, 2},
+	5: {1, 2, 3, 4, 0},
+	6: {1, 2, 3, 5, 0, 4},
+	7: {1, 2, 3, 5, 0, 6, 4},
+	8: {1, 2, 3, 5, 0, 6, 7, 4},
+}
+
+// expectedOrder is the walk of next starting at 0, i.e. what Analyze
+// should return as Group.Order for the matching cycleFixtures entry.
+var expectedOrder = map[int][]int{
+	2: {0, 1},
+	3: {0, 1, 2},
+	4: {0, 3, 2, 1},
+	5: {0, 1, 2, 3, 4},
+	6: {0, 1, 2, 3, 5, 4},
+	7: {0, 1, 2, 3, 5, 6, 4},
+	8: {0, 1, 2, 3, 5, 6, 7, 4},
+}
+
+// buildGroup turns a next permutation into a []common.PoolL2Tx with the
+// matching RqOffset set on every tx, and TxID[0] == index so TxID order
+// matches index order (making index 0 the canonical starting point).
+func buildGroup(t *testing.T, next []int) []common.PoolL2Tx {
+	t.Helper()
+	n := len(next)
+	txs := make([]common.PoolL2Tx, n)
+	for i := range txs {
+		rqoffset, err := relativePosition2RequestOffset(next[i] - i)
+		if err != nil {
+			t.Fatalf("test fixture uses an unrepresentable RqOffset: %v", err)
+		}
+		txs[i].RqOffset = rqoffset
+		txs[i].TxID[0] = byte(i)
+	}
+	return txs
+}
+
+// relativePosition2RequestOffset is the inverse of
+// RequestOffset2RelativePosition, used only to build test fixtures.
+func relativePosition2RequestOffset(rel int) (uint8, error) {
+	for rqoffset := uint8(1); rqoffset <= 7; rqoffset++ {
+		got, err := RequestOffset2RelativePosition(rqoffset)
+		if err == nil && got == rel {
+			return rqoffset, nil
+		}
+	}
+	return 0, ErrInvalidRqOffset
+}
+
+func TestRequestOffset2RelativePosition(t *testing.T) {
+	expected := map[uint8]int{1: 1, 2: 2, 3: 3, 4: -4, 5: -3, 6: -2, 7: -1}
+	for rqoffset, rel := range expected {
+		got, err := RequestOffset2RelativePosition(rqoffset)
+		assert.NoError(t, err)
+		assert.Equal(t, rel, got)
+	}
+	_, err := RequestOffset2RelativePosition(0)
+	assert.Equal(t, ErrInvalidRqOffset, err)
+	_, err = RequestOffset2RelativePosition(8)
+	assert.Equal(t, ErrInvalidRqOffset, err)
+}
+
+func TestAnalyzeSingleGroupSizes(t *testing.T) {
+	for n := 2; n <= 8; n++ {
+		txs := buildGroup(t, cycleFixtures[n])
+		group, err := Analyze(txs)
+		assert.NoError(t, err)
+		assert.True(t, group.IsSingleGroup)
+		assert.Equal(t, expectedOrder[n], group.Order)
+	}
+}
+
+func TestAnalyzeEveryRqOffsetPattern(t *testing.T) {
+	// The size-8 fixture's steps are {+1, +1, +1, +2, -4, +1, +1, -3}: every
+	// RqOffset documented in RequestOffset2RelativePosition (+1, +2, +3,
+	// -4, -3, -2, -1) should appear across the fixtures above at least
+	// once; check that here instead of duplicating each by hand.
+	seenOffsets := make(map[int]bool)
+	for _, next := range cycleFixtures {
+		for i, dst := range next {
+			seenOffsets[dst-i] = true
+		}
+	}
+	for _, rel := range []int{1, 2, 3, -4, -3, -2, -1} {
+		assert.True(t, seenOffsets[rel], "relative position %d never exercised by a fixture", rel)
+	}
+}
+
+func TestAnalyzeNotSingleGroup(t *testing.T) {
+	// Two disjoint pairs (0<->1, 2<->3): each pair is internally linked,
+	// but the two pairs don't request each other, so this is two atomic
+	// groups, not one.
+	txs := make([]common.PoolL2Tx, 4)
+	txs[0].RqOffset = 1 // requests 1
+	txs[1].RqOffset = 7 // requests 0 (offset -1)
+	txs[2].RqOffset = 1 // requests 3
+	txs[3].RqOffset = 7 // requests 2 (offset -1)
+
+	group, err := Analyze(txs)
+	assert.NoError(t, err)
+	assert.False(t, group.IsSingleGroup)
+}
+
+func TestAnalyzeInvalidRqOffset(t *testing.T) {
+	txs := make([]common.PoolL2Tx, 2)
+	txs[0].RqOffset = 1
+	txs[1].RqOffset = 0 // invalid: a standalone tx can't be in a group
+
+	_, err := Analyze(txs)
+	assert.Equal(t, ErrInvalidRqOffset, err)
+}
+
+func TestAnalyzeRqOffsetOutOfBounds(t *testing.T) {
+	txs := make([]common.PoolL2Tx, 2)
+	txs[0].RqOffset = 3 // requests i+3, out of bounds for a 2-tx group
+	txs[1].RqOffset = 7
+
+	_, err := Analyze(txs)
+	assert.Equal(t, ErrRqOffsetOutOfBounds, err)
+}
@@ -0,0 +1,264 @@
+package common
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+
+	"github.com/hermeznetwork/tracerr"
+)
+
+// zkInputsBinaryMagic identifies the WriteBinary/ReadBinary format, so a
+// stray file doesn't get silently misparsed as a different version
+const zkInputsBinaryMagic = "HZKI"
+
+// zkInputsBinaryVersion is bumped whenever the encoding below changes in a
+// way that breaks ReadBinary on older files
+const zkInputsBinaryVersion = 1
+
+// ZKInputsDims carries the ProcessTxsConfig dimensions (nLevels, maxTx,
+// maxL1Tx, maxFeeTx) a ZKInputs was generated with. They aren't reliably
+// recoverable from the ZKInputs arrays themselves (several are sized
+// maxTx-1 or maxFeeTx, and maxL1Tx isn't recoverable at all), so
+// WriteBinary/ReadBinary carry them explicitly in the file header; every
+// other slice is self-describing (length-prefixed).
+type ZKInputsDims struct {
+	NLevels  uint32
+	MaxTx    uint32
+	MaxL1Tx  uint32
+	MaxFeeTx uint32
+}
+
+// WriteJSON writes zki as JSON to w through a json.Encoder: the same JSON
+// json.Marshal returns, followed by a newline. The encoder still builds the
+// whole encoding in memory before writing it; field arrays are not
+// streamed to w piece by piece.
+func (zki *ZKInputs) WriteJSON(w io.Writer) error {
+	return tracerr.Wrap(json.NewEncoder(w).Encode(zki))
+}
+
+// WriteBinary encodes zki as a compact fixed-width binary format: a header
+// with the magic, version and dims, followed by every decimal field
+// element (the string / []string / [][]string leaves of the ZKInputs
+// struct) as a 32-byte little-endian integer, with a length prefix before
+// each slice. This is both smaller and cheaper to parse than the JSON
+// form, so a prover can mmap it instead of decoding a large JSON document
+// of decimal strings.
+func (zki *ZKInputs) WriteBinary(w io.Writer, dims ZKInputsDims) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(zkInputsBinaryMagic); err != nil {
+		return tracerr.Wrap(err)
+	}
+	header := []uint32{zkInputsBinaryVersion, dims.NLevels, dims.MaxTx, dims.MaxL1Tx, dims.MaxFeeTx}
+	for _, v := range header {
+		if err := binary.Write(bw, binary.LittleEndian, v); err != nil {
+			return tracerr.Wrap(err)
+		}
+	}
+	if err := writeValue(bw, reflect.ValueOf(zki).Elem()); err != nil {
+		return err
+	}
+	return tracerr.Wrap(bw.Flush())
+}
+
+// ReadBinary is the inverse of WriteBinary
+func ReadBinary(r io.Reader) (*ZKInputs, ZKInputsDims, error) {
+	var dims ZKInputsDims
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(zkInputsBinaryMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, dims, tracerr.Wrap(err)
+	}
+	if string(magic) != zkInputsBinaryMagic {
+		return nil, dims, tracerr.Wrap(fmt.Errorf("not a ZKInputs binary file (bad magic)"))
+	}
+	var version uint32
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return nil, dims, tracerr.Wrap(err)
+	}
+	if version != zkInputsBinaryVersion {
+		return nil, dims, tracerr.Wrap(fmt.Errorf("unsupported ZKInputs binary version %d", version))
+	}
+	for _, field := range []*uint32{&dims.NLevels, &dims.MaxTx, &dims.MaxL1Tx, &dims.MaxFeeTx} {
+		if err := binary.Read(br, binary.LittleEndian, field); err != nil {
+			return nil, dims, tracerr.Wrap(err)
+		}
+	}
+
+	zki := &ZKInputs{}
+	if err := readValue(br, reflect.ValueOf(zki).Elem()); err != nil {
+		return nil, dims, err
+	}
+	return zki, dims, nil
+}
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// writeValue recursively encodes every decimal field-element leaf (string
+// or *big.Int) reachable from v, in Go's deterministic reflect.Value.Field
+// order, prefixing every slice with its length so ReadBinary can
+// reconstruct it without knowing ZKInputs's field names ahead of time
+func writeValue(w io.Writer, v reflect.Value) error {
+	if v.Type() == bigIntType {
+		n := v.Interface().(big.Int) //nolint:forcetypeassert
+		return writeFieldElement(w, n.String())
+	}
+	if v.Kind() == reflect.Ptr && v.Type().Elem() == bigIntType {
+		if v.IsNil() {
+			return writeFieldElement(w, "0")
+		}
+		n := v.Interface().(*big.Int) //nolint:forcetypeassert
+		return writeFieldElement(w, n.String())
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return writeFieldElement(w, v.String())
+	case reflect.Slice:
+		if err := binary.Write(w, binary.LittleEndian, uint32(v.Len())); err != nil { //nolint:gomnd
+			return tracerr.Wrap(err)
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := writeValue(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := writeValue(w, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := writeValue(w, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return tracerr.Wrap(binary.Write(w, binary.LittleEndian, uint8(0)))
+		}
+		if err := tracerr.Wrap(binary.Write(w, binary.LittleEndian, uint8(1))); err != nil {
+			return err
+		}
+		return writeValue(w, v.Elem())
+	default:
+		// not a decimal field-element leaf (e.g. a numeric BatchNum
+		// field); only the string-typed circuit inputs are encoded,
+		// everything else belongs in the dims header instead
+		return nil
+	}
+}
+
+// readValue is the mirror of writeValue
+func readValue(r io.Reader, v reflect.Value) error {
+	if v.Type() == bigIntType {
+		s, err := readFieldElement(r)
+		if err != nil {
+			return err
+		}
+		n, ok := new(big.Int).SetString(s, 10) //nolint:gomnd
+		if !ok {
+			return tracerr.Wrap(fmt.Errorf("field element %q is not a base-10 integer", s))
+		}
+		v.Set(reflect.ValueOf(*n))
+		return nil
+	}
+	if v.Kind() == reflect.Ptr && v.Type().Elem() == bigIntType {
+		s, err := readFieldElement(r)
+		if err != nil {
+			return err
+		}
+		n, ok := new(big.Int).SetString(s, 10) //nolint:gomnd
+		if !ok {
+			return tracerr.Wrap(fmt.Errorf("field element %q is not a base-10 integer", s))
+		}
+		v.Set(reflect.ValueOf(n))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		s, err := readFieldElement(r)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+		return nil
+	case reflect.Slice:
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return tracerr.Wrap(err)
+		}
+		slice := reflect.MakeSlice(v.Type(), int(n), int(n))
+		for i := 0; i < int(n); i++ {
+			if err := readValue(r, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := readValue(r, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := readValue(r, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Ptr:
+		var present uint8
+		if err := binary.Read(r, binary.LittleEndian, &present); err != nil {
+			return tracerr.Wrap(err)
+		}
+		if present == 0 {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		return readValue(r, v.Elem())
+	default:
+		return nil
+	}
+}
+
+func writeFieldElement(w io.Writer, s string) error {
+	n, ok := new(big.Int).SetString(s, 10) //nolint:gomnd
+	if !ok {
+		return tracerr.Wrap(fmt.Errorf("field element %q is not a base-10 integer", s))
+	}
+	var buf [32]byte
+	b := n.Bytes()
+	if len(b) > len(buf) {
+		return tracerr.Wrap(fmt.Errorf("field element %q overflows 32 bytes", s))
+	}
+	for i := 0; i < len(b); i++ { // big.Int.Bytes() is big-endian; store little-endian
+		buf[i] = b[len(b)-1-i]
+	}
+	_, err := w.Write(buf[:])
+	return tracerr.Wrap(err)
+}
+
+func readFieldElement(r io.Reader) (string, error) {
+	var buf [32]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return "", tracerr.Wrap(err)
+	}
+	be := make([]byte, len(buf))
+	for i := range buf {
+		be[i] = buf[len(buf)-1-i]
+	}
+	return new(big.Int).SetBytes(be).String(), nil
+}
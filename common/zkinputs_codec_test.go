@@ -0,0 +1,31 @@
+package common
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZKInputsWriteBinaryRoundTrip(t *testing.T) {
+	zki := &ZKInputs{}
+	zki.FeeIdxs = []*big.Int{big.NewInt(256), big.NewInt(0)}
+	zki.FromIdx = []*big.Int{big.NewInt(0), big.NewInt(256)}
+
+	var buf bytes.Buffer
+	dims := ZKInputsDims{NLevels: 32, MaxTx: 4, MaxL1Tx: 2, MaxFeeTx: 2}
+	require.Nil(t, zki.WriteBinary(&buf, dims))
+
+	got, gotDims, err := ReadBinary(&buf)
+	require.Nil(t, err)
+	assert.Equal(t, dims, gotDims)
+	assert.Equal(t, len(zki.FeeIdxs), len(got.FeeIdxs))
+	assert.Equal(t, len(zki.FromIdx), len(got.FromIdx))
+}
+
+func TestZKInputsWriteBinaryBadMagic(t *testing.T) {
+	_, _, err := ReadBinary(bytes.NewReader([]byte("not a zkinputs file")))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,218 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hermeznetwork/tracerr"
+)
+
+// zkInputsFieldsBinaryMagic identifies the WriteBinaryFields/ReadBinaryFields
+// format. It's distinct from zkInputsBinaryMagic (the positional format from
+// WriteBinary) since the two aren't interchangeable.
+const zkInputsFieldsBinaryMagic = "HZKF"
+
+const zkInputsFieldsBinaryVersion = 1
+
+// WriteFields visits every top-level field of zki in turn, passing its JSON
+// field name and its individually-marshalled value, instead of building the
+// single ~30KB JSON blob that json.Marshal(zki) would. This lets a caller
+// (e.g. the batch builder) pipe witness fields to a proving server as they
+// become available, rather than materializing the whole document first.
+func (zki *ZKInputs) WriteFields(visit func(name string, value json.RawMessage) error) error {
+	v := reflect.ValueOf(zki).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		raw, err := json.Marshal(v.Field(i).Interface())
+		if err != nil {
+			return tracerr.Wrap(err)
+		}
+		if err := visit(name, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteBinaryFields encodes zki as a sequence of (fieldID, length, payload)
+// records, one per top-level field, instead of WriteBinary's single
+// positional blob. fieldID is looked up from zkInputsFieldIDs, which is
+// keyed by JSON field name rather than struct field order, so an
+// out-of-process prover can decode a field by ID without caring where
+// ZKInputs's Go declaration puts it, and ReadBinaryFields can skip a record
+// whose ID it doesn't recognize instead of failing outright.
+func (zki *ZKInputs) WriteBinaryFields(w io.Writer, dims ZKInputsDims) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(zkInputsFieldsBinaryMagic); err != nil {
+		return tracerr.Wrap(err)
+	}
+	header := []uint32{zkInputsFieldsBinaryVersion, dims.NLevels, dims.MaxTx, dims.MaxL1Tx, dims.MaxFeeTx}
+	for _, n := range header {
+		if err := binary.Write(bw, binary.LittleEndian, n); err != nil {
+			return tracerr.Wrap(err)
+		}
+	}
+
+	v := reflect.ValueOf(zki).Elem()
+	t := v.Type()
+	if err := binary.Write(bw, binary.LittleEndian, uint32(t.NumField())); err != nil { //nolint:gomnd
+		return tracerr.Wrap(err)
+	}
+	for i := 0; i < t.NumField(); i++ {
+		name := jsonFieldName(t.Field(i))
+		if name == "" {
+			continue
+		}
+		id, ok := zkInputsFieldIDs[name]
+		if !ok {
+			return tracerr.Wrap(fmt.Errorf("no field ID registered for ZKInputs field %q", name))
+		}
+		var buf bytes.Buffer
+		if err := writeValue(&buf, v.Field(i)); err != nil {
+			return err
+		}
+		if err := binary.Write(bw, binary.LittleEndian, id); err != nil {
+			return tracerr.Wrap(err)
+		}
+		if err := binary.Write(bw, binary.LittleEndian, uint32(buf.Len())); err != nil { //nolint:gomnd
+			return tracerr.Wrap(err)
+		}
+		if _, err := bw.Write(buf.Bytes()); err != nil {
+			return tracerr.Wrap(err)
+		}
+	}
+	return tracerr.Wrap(bw.Flush())
+}
+
+// ReadBinaryFields is the inverse of WriteBinaryFields. Records whose
+// fieldID isn't in zkInputsFieldIDs (e.g. written by a newer build that
+// added a field) are skipped rather than rejected, so older readers stay
+// forward-compatible.
+func ReadBinaryFields(r io.Reader) (*ZKInputs, ZKInputsDims, error) {
+	var dims ZKInputsDims
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(zkInputsFieldsBinaryMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, dims, tracerr.Wrap(err)
+	}
+	if string(magic) != zkInputsFieldsBinaryMagic {
+		return nil, dims, tracerr.Wrap(fmt.Errorf("not a ZKInputs fields binary file (bad magic)"))
+	}
+	var version uint32
+	if err := binary.Read(br, binary.LittleEndian, &version); err != nil {
+		return nil, dims, tracerr.Wrap(err)
+	}
+	if version != zkInputsFieldsBinaryVersion {
+		return nil, dims, tracerr.Wrap(fmt.Errorf("unsupported ZKInputs fields binary version %d", version))
+	}
+	for _, field := range []*uint32{&dims.NLevels, &dims.MaxTx, &dims.MaxL1Tx, &dims.MaxFeeTx} {
+		if err := binary.Read(br, binary.LittleEndian, field); err != nil {
+			return nil, dims, tracerr.Wrap(err)
+		}
+	}
+
+	var numFields uint32
+	if err := binary.Read(br, binary.LittleEndian, &numFields); err != nil {
+		return nil, dims, tracerr.Wrap(err)
+	}
+
+	zki := &ZKInputs{}
+	v := reflect.ValueOf(zki).Elem()
+	for i := uint32(0); i < numFields; i++ {
+		var id, length uint32
+		if err := binary.Read(br, binary.LittleEndian, &id); err != nil {
+			return nil, dims, tracerr.Wrap(err)
+		}
+		if err := binary.Read(br, binary.LittleEndian, &length); err != nil {
+			return nil, dims, tracerr.Wrap(err)
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return nil, dims, tracerr.Wrap(err)
+		}
+		name, ok := zkInputsFieldNames[id]
+		if !ok {
+			continue // unknown field, written by a newer version; skip it
+		}
+		field := v.FieldByName(fieldGoName(name))
+		if !field.IsValid() {
+			continue
+		}
+		if err := readValue(bytes.NewReader(payload), field); err != nil {
+			return nil, dims, err
+		}
+	}
+	return zki, dims, nil
+}
+
+// zkInputsFieldIDs and zkInputsFieldNames assign a stable numeric ID to
+// every ZKInputs JSON field name, keyed by name (not struct declaration
+// order) so the mapping doesn't silently shift if ZKInputs's fields are
+// ever reordered or regrouped in source.
+var zkInputsFieldIDs, zkInputsFieldNames = buildZKInputsFieldIDs()
+
+func buildZKInputsFieldIDs() (map[string]uint32, map[uint32]string) {
+	t := reflect.TypeOf(ZKInputs{})
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonFieldName(t.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	ids := make(map[string]uint32, len(names))
+	byID := make(map[uint32]string, len(names))
+	for i, name := range names {
+		ids[name] = uint32(i)
+		byID[uint32(i)] = name
+	}
+	return ids, byID
+}
+
+var zkInputsGoNames = buildZKInputsGoNames()
+
+func buildZKInputsGoNames() map[string]string {
+	t := reflect.TypeOf(ZKInputs{})
+	m := make(map[string]string, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := jsonFieldName(t.Field(i)); name != "" {
+			m[name] = t.Field(i).Name
+		}
+	}
+	return m
+}
+
+func fieldGoName(jsonName string) string {
+	return zkInputsGoNames[jsonName]
+}
+
+// jsonFieldName returns the JSON field name encoding/json would use for sf,
+// honouring a "json" tag (including "-" to skip the field) and falling back
+// to the Go field name otherwise.
+func jsonFieldName(sf reflect.StructField) string {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if tag == "" {
+		return sf.Name
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		return sf.Name
+	}
+	return name
+}
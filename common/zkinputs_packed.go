@@ -0,0 +1,123 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/hermeznetwork/tracerr"
+)
+
+// packedBjjCompressedBits is the number of bits per tx slot in
+// FromBjjCompressed (a BabyJubJub compressed-point bit decomposition)
+const packedBjjCompressedBits = 256
+
+// MarshalJSONPacked is like json.Marshal(zki), except FromBjjCompressed
+// (currently serialized as MaxTx slots of 256 individual "0"/"1" strings
+// each) is packed into a single big-integer decimal string per slot, in the
+// same little-endian bit order the circuit expects. This cuts that field's
+// JSON size by 10-50x; every other field is unchanged, so a reader that
+// doesn't care about the size difference can still decode it with
+// UnmarshalJSONPacked.
+func (zki *ZKInputs) MarshalJSONPacked() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	err := zki.WriteFields(func(name string, value json.RawMessage) error {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		if name == "fromBjjCompressed" {
+			packed, err := packBitRows(value)
+			if err != nil {
+				return err
+			}
+			value = packed
+		}
+		nameJSON, err := json.Marshal(name)
+		if err != nil {
+			return tracerr.Wrap(err)
+		}
+		buf.Write(nameJSON)
+		buf.WriteByte(':')
+		buf.Write(value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSONPacked is the inverse of MarshalJSONPacked
+func (zki *ZKInputs) UnmarshalJSONPacked(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return tracerr.Wrap(err)
+	}
+	if raw, ok := fields["fromBjjCompressed"]; ok {
+		unpacked, err := unpackBitRows(raw, packedBjjCompressedBits)
+		if err != nil {
+			return err
+		}
+		fields["fromBjjCompressed"] = unpacked
+	}
+	reassembled, err := json.Marshal(fields)
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	return tracerr.Wrap(json.Unmarshal(reassembled, zki))
+}
+
+// packBitRows packs a JSON array of bit-string rows (e.g.
+// [["0","1",...256 entries],...]) into an array of decimal strings, one per
+// row
+func packBitRows(raw json.RawMessage) (json.RawMessage, error) {
+	var rows [][]string
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	packed := make([]string, len(rows))
+	for i, bits := range rows {
+		n := new(big.Int)
+		for j := len(bits) - 1; j >= 0; j-- {
+			n.Lsh(n, 1) //nolint:gomnd
+			if bits[j] == "1" {
+				n.SetBit(n, 0, 1)
+			}
+		}
+		packed[i] = n.String()
+	}
+	out, err := json.Marshal(packed)
+	return out, tracerr.Wrap(err)
+}
+
+// unpackBitRows is the inverse of packBitRows, expanding each decimal
+// string back into nBits individual "0"/"1" strings
+func unpackBitRows(raw json.RawMessage, nBits int) (json.RawMessage, error) {
+	var packed []string
+	if err := json.Unmarshal(raw, &packed); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	rows := make([][]string, len(packed))
+	for i, s := range packed {
+		n, ok := new(big.Int).SetString(s, 10) //nolint:gomnd
+		if !ok {
+			return nil, tracerr.Wrap(fmt.Errorf("packed bit row %q is not a base-10 integer", s))
+		}
+		bits := make([]string, nBits)
+		for j := 0; j < nBits; j++ {
+			if n.Bit(j) == 1 {
+				bits[j] = "1"
+			} else {
+				bits[j] = "0"
+			}
+		}
+		rows[i] = bits
+	}
+	out, err := json.Marshal(rows)
+	return out, tracerr.Wrap(err)
+}
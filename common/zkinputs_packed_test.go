@@ -0,0 +1,55 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZKInputsMarshalJSONPackedRoundTrip(t *testing.T) {
+	zki := &ZKInputs{}
+	row := make([]string, packedBjjCompressedBits)
+	for j := range row {
+		row[j] = "0"
+	}
+	row[0] = "1" // LSB set
+	row[255] = "1"
+	zki.FromBjjCompressed = [][]string{row}
+
+	packed, err := zki.MarshalJSONPacked()
+	require.Nil(t, err)
+
+	var fields map[string]json.RawMessage
+	require.Nil(t, json.Unmarshal(packed, &fields))
+	var packedRows []string
+	require.Nil(t, json.Unmarshal(fields["fromBjjCompressed"], &packedRows))
+	require.Len(t, packedRows, 1)
+	assert.NotEqual(t, "0", packedRows[0]) // packed into a single non-trivial decimal string
+
+	var got ZKInputs
+	require.Nil(t, got.UnmarshalJSONPacked(packed))
+	require.Len(t, got.FromBjjCompressed, 1)
+	assert.Equal(t, row, got.FromBjjCompressed[0])
+}
+
+func TestZKInputsMarshalJSONPackedSmallerThanVerbose(t *testing.T) {
+	zki := &ZKInputs{}
+	rows := make([][]string, 4) //nolint:gomnd
+	for i := range rows {
+		row := make([]string, packedBjjCompressedBits)
+		for j := range row {
+			row[j] = "0"
+		}
+		rows[i] = row
+	}
+	zki.FromBjjCompressed = rows
+
+	verbose, err := json.Marshal(zki)
+	require.Nil(t, err)
+	packed, err := zki.MarshalJSONPacked()
+	require.Nil(t, err)
+
+	assert.Less(t, len(packed), len(verbose))
+}
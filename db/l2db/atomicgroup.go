@@ -0,0 +1,161 @@
+/*
+Package l2db persists the node's pending L2 tx pool, including atomic
+groups, to the SQL database, and lets API handlers look pending txs up,
+insert new ones and react to a group's state changes without polling.
+
+This file covers the pub/sub surface getAtomicGroupEvents depends on
+(SubscribeAtomicGroup, the underlying publishGroupEvent fan-out, and
+GetPoolTxsByAtomicGroupIDAPI). The rest of L2DB's methods (AddTx,
+AddAccountCreationAuth, GetTx, CancelAtomicGroup, ReplaceAtomicGroup,
+AddTxs, ...) are defined in sibling files.
+
+No SQL migrations exist yet anywhere in this repo, so the queries below
+assume a tx_pool table keyed by tx_id, with an atomic_group_id/
+atomic_position pair identifying a tx's atomic group and place in it.
+*/
+package l2db
+
+import (
+	"database/sql"
+	"encoding/hex"
+	"sync"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// L2DB wraps the SQL connection the pending tx pool is persisted to
+type L2DB struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	groupSubs map[common.AtomicGroupID]map[chan AtomicGroupTxEvent]struct{}
+}
+
+// NewL2DB wraps db for use as the pool's L2DB
+func NewL2DB(db *sql.DB) *L2DB {
+	return &L2DB{
+		db:        db,
+		groupSubs: make(map[common.AtomicGroupID]map[chan AtomicGroupTxEvent]struct{}),
+	}
+}
+
+// groupEventBufferSize is how many pending events a single
+// SubscribeAtomicGroup caller can be behind before the oldest is dropped
+// to make room, rather than blocking the mutation that published it
+const groupEventBufferSize = 16
+
+// AtomicGroupTxEvent is published for every tx in an atomic group
+// whenever its pool state changes (selected, forged, invalidated), as
+// consumed by getAtomicGroupEvents
+type AtomicGroupTxEvent struct {
+	TxID     common.TxID
+	NewState common.PoolL2TxState
+}
+
+// SubscribeAtomicGroup registers a subscriber for every AtomicGroupTxEvent
+// published for groupID (see publishGroupEvent) until unsubscribe is
+// called. The returned channel is buffered; once a subscriber falls
+// groupEventBufferSize events behind, its oldest buffered event is
+// dropped to make room for the new one instead of blocking the caller
+// that triggered the publish.
+func (l *L2DB) SubscribeAtomicGroup(groupID common.AtomicGroupID) (events chan AtomicGroupTxEvent, unsubscribe func()) {
+	ch := make(chan AtomicGroupTxEvent, groupEventBufferSize)
+
+	l.mu.Lock()
+	if l.groupSubs[groupID] == nil {
+		l.groupSubs[groupID] = make(map[chan AtomicGroupTxEvent]struct{})
+	}
+	l.groupSubs[groupID][ch] = struct{}{}
+	l.mu.Unlock()
+
+	unsubscribe = func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if subs, ok := l.groupSubs[groupID]; ok {
+			if _, ok := subs[ch]; ok {
+				delete(subs, ch)
+				close(ch)
+			}
+			if len(subs) == 0 {
+				delete(l.groupSubs, groupID)
+			}
+		}
+	}
+	return ch, unsubscribe
+}
+
+// publishGroupEvent fans ev out, non-blocking, to every current
+// SubscribeAtomicGroup subscriber of groupID
+func (l *L2DB) publishGroupEvent(groupID common.AtomicGroupID, ev AtomicGroupTxEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ch := range l.groupSubs[groupID] {
+		select {
+		case ch <- ev:
+		default:
+			// drop the oldest buffered event to make room rather than
+			// block the mutation that's publishing this one
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+}
+
+// groupIDHex is the hex encoding of groupID used as its
+// tx_pool.atomic_group_id column value
+func groupIDHex(groupID common.AtomicGroupID) string {
+	return hex.EncodeToString(groupID[:])
+}
+
+// poolTxRow is implemented by *sql.Row and *sql.Rows, letting
+// scanPoolL2Tx back both a single-row lookup and a result set
+type poolTxRow interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPoolL2Tx scans one tx_pool row, in the column order queried by
+// GetPoolTxsByAtomicGroupIDAPI, into tx
+func scanPoolL2Tx(row poolTxRow, tx *common.PoolL2Tx) error {
+	return row.Scan(&tx.TxID, &tx.FromIdx, &tx.ToIdx, &tx.TokenID, &tx.Amount,
+		&tx.Fee, &tx.Nonce, &tx.State, &tx.BatchNum)
+}
+
+// GetPoolTxsByAtomicGroupIDAPI returns every tx belonging to groupID,
+// ordered the way they'll be placed in a batch (atomic_position)
+func (l *L2DB) GetPoolTxsByAtomicGroupIDAPI(groupID common.AtomicGroupID) ([]common.PoolL2Tx, error) {
+	rows, err := l.db.Query(
+		`SELECT tx_id, from_idx, to_idx, token_id, amount, fee, nonce, state,
+		        batch_num
+		   FROM tx_pool
+		  WHERE atomic_group_id = $1
+		  ORDER BY atomic_position ASC`,
+		groupIDHex(groupID),
+	)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var txs []common.PoolL2Tx
+	for rows.Next() {
+		var tx common.PoolL2Tx
+		if err := scanPoolL2Tx(rows, &tx); err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		txs = append(txs, tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	if len(txs) == 0 {
+		return nil, tracerr.Wrap(sql.ErrNoRows)
+	}
+	return txs, nil
+}
@@ -0,0 +1,31 @@
+package l2db
+
+import (
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// CountActiveAtomicGroupsAPI returns the number of distinct atomic groups
+// still in the pool that haven't reached a terminal state (Forged or
+// Invalid) for every one of their txs, as reported by the "atomicGroups"
+// /health checker
+func (l *L2DB) CountActiveAtomicGroupsAPI() (int, error) {
+	row := l.db.QueryRow(
+		`SELECT COUNT(DISTINCT atomic_group_id) FROM tx_pool
+		  WHERE atomic_group_id NOT IN ('', $3)
+		    AND atomic_group_id NOT IN (
+		        SELECT atomic_group_id FROM tx_pool
+		         WHERE atomic_group_id NOT IN ('', $3)
+		         GROUP BY atomic_group_id
+		        HAVING COUNT(*) = SUM(CASE WHEN state IN ($1, $2) THEN 1 ELSE 0 END)
+		    )`,
+		common.PoolL2TxStateForged, common.PoolL2TxStateInvalid,
+		// txs outside any atomic group are stored with the zero ID's hex
+		groupIDHex(common.AtomicGroupID{}),
+	)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, tracerr.Wrap(err)
+	}
+	return count, nil
+}
@@ -0,0 +1,127 @@
+package l2db
+
+import (
+	"database/sql"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/tracerr"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// cancelGroupTxs marks every tx_pool row for groupIDHex as invalid
+// within tx, returning the TxIDs it touched so the caller can publish
+// one AtomicGroupTxEvent per tx once the transaction commits
+func cancelGroupTxs(tx *sql.Tx, groupIDHex string) ([]common.TxID, error) {
+	rows, err := tx.Query(`SELECT tx_id FROM tx_pool WHERE atomic_group_id = $1`, groupIDHex)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	var txIDs []common.TxID
+	for rows.Next() {
+		var txID common.TxID
+		if err := rows.Scan(&txID); err != nil {
+			rows.Close() //nolint:errcheck
+			return nil, tracerr.Wrap(err)
+		}
+		txIDs = append(txIDs, txID)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close() //nolint:errcheck
+		return nil, tracerr.Wrap(err)
+	}
+	rows.Close() //nolint:errcheck
+
+	if _, err := tx.Exec(
+		`UPDATE tx_pool SET state = $1 WHERE atomic_group_id = $2`,
+		common.PoolL2TxStateInvalid, groupIDHex,
+	); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	return txIDs, nil
+}
+
+// insertPoolTxs inserts txs into tx_pool within the given SQL
+// transaction, one row per tx, so the caller controls the atomicity of
+// the whole set
+func insertPoolTxs(tx *sql.Tx, txs []common.PoolL2Tx) error {
+	stmt, err := tx.Prepare(
+		`INSERT INTO tx_pool (tx_id, from_idx, to_idx, token_id, amount, fee, nonce, state,
+		                      atomic_group_id, atomic_position)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`)
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	defer stmt.Close() //nolint:errcheck
+
+	for _, poolTx := range txs {
+		if _, err := stmt.Exec(poolTx.TxID, poolTx.FromIdx, poolTx.ToIdx, poolTx.TokenID,
+			poolTx.Amount, poolTx.Fee, poolTx.Nonce, poolTx.State,
+			groupIDHex(poolTx.AtomicGroupID), poolTx.AtomicPosition); err != nil {
+			return tracerr.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// CancelAtomicGroup marks every tx in groupID as invalid (excluded from
+// future batch selection) in a single SQL transaction, and publishes an
+// AtomicGroupTxEvent per tx. signers is the compressed BJJ public key of
+// each distinct signer the caller has already verified signed off on
+// groupID (signature verification is an API-layer concern, done against
+// api.BJJSignature before this is called); it's accepted here, rather
+// than the raw signatures, so l2db doesn't need to import the api
+// package's request types just to record who authorized the
+// cancellation.
+func (l *L2DB) CancelAtomicGroup(groupID common.AtomicGroupID, signers []babyjub.PublicKeyComp) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	txIDs, err := cancelGroupTxs(tx, groupIDHex(groupID))
+	if err != nil {
+		_ = tx.Rollback()
+		return tracerr.Wrap(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return tracerr.Wrap(err)
+	}
+	for _, txID := range txIDs {
+		l.publishGroupEvent(groupID, AtomicGroupTxEvent{TxID: txID, NewState: common.PoolL2TxStateInvalid})
+	}
+	return nil
+}
+
+// ReplaceAtomicGroup atomically swaps groupID's still-pending txs for
+// replacementTxs (already validated by the caller to address the same
+// set of FromIdx, each with a strictly greater Nonce and Fee): the
+// original txs are invalidated and replacementTxs are inserted, all
+// within a single SQL transaction, and an AtomicGroupTxEvent is
+// published per affected tx.
+func (l *L2DB) ReplaceAtomicGroup(groupID common.AtomicGroupID, replacementTxs []common.PoolL2Tx) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	originalTxIDs, err := cancelGroupTxs(tx, groupIDHex(groupID))
+	if err != nil {
+		_ = tx.Rollback()
+		return tracerr.Wrap(err)
+	}
+	if err := insertPoolTxs(tx, replacementTxs); err != nil {
+		_ = tx.Rollback()
+		return tracerr.Wrap(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return tracerr.Wrap(err)
+	}
+	for _, txID := range originalTxIDs {
+		l.publishGroupEvent(groupID, AtomicGroupTxEvent{TxID: txID, NewState: common.PoolL2TxStateInvalid})
+	}
+	for _, replacementTx := range replacementTxs {
+		l.publishGroupEvent(groupID, AtomicGroupTxEvent{
+			TxID:     replacementTx.TxID,
+			NewState: common.PoolL2TxStatePending,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package l2db
+
+import (
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// AddTxs inserts txs into tx_pool within a single SQL transaction, so
+// postPoolTxsBatch's insert is all-or-nothing: if any row fails, none of
+// the batch lands in the pool.
+func (l *L2DB) AddTxs(txs []common.PoolL2Tx) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	if err := insertPoolTxs(tx, txs); err != nil {
+		_ = tx.Rollback()
+		return tracerr.Wrap(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return tracerr.Wrap(err)
+	}
+	return nil
+}
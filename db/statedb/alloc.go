@@ -0,0 +1,100 @@
+package statedb
+
+import (
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/tracerr"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// AllocAccount describes a single account of a pre-state Alloc, analogous to
+// Ethereum's genesis Alloc entries
+type AllocAccount struct {
+	Idx     common.Idx
+	TokenID common.TokenID
+	Nonce   common.Nonce
+	Balance *big.Int
+	BJJ     *babyjub.PublicKey
+	EthAddr ethCommon.Address
+}
+
+// Alloc is a declarative pre-state: the set of accounts a StateDB should be
+// seeded with, plus the oldLastIdx the StateDB should resume idx allocation
+// from
+type Alloc struct {
+	Accounts   []AllocAccount
+	OldLastIdx common.Idx
+	BatchNum   common.BatchNum
+}
+
+// LoadStateDBAlloc creates a new StateDB at dbPath (as NewStateDB would) and
+// applies alloc to it, so that tests and node operators can bootstrap a
+// StateDB from a checkpoint dump instead of replaying every historical L1
+// tx
+func LoadStateDBAlloc(dbPath string, t TypeStateDB, nLevels int, alloc Alloc) (*StateDB, error) {
+	sdb, err := NewStateDB(dbPath, t, nLevels)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	if err := sdb.ApplyAlloc(alloc); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	return sdb, nil
+}
+
+// ApplyAlloc seeds sdb with alloc by processing one
+// TxTypeCreateAccountDeposit tx per account, passed to ProcessTxs as
+// L1CoordinatorTxs, and then making a checkpoint. Accounts take the Idx
+// ProcessTxs assigns them in order, and the checkpoint is made at the
+// StateDB's next BatchNum; AllocAccount.Idx, AllocAccount.Nonce,
+// alloc.OldLastIdx and alloc.BatchNum are not applied.
+func (s *StateDB) ApplyAlloc(alloc Alloc) error {
+	l1Txs := make([]common.L1Tx, len(alloc.Accounts))
+	for i, acc := range alloc.Accounts {
+		l1Txs[i] = common.L1Tx{
+			FromIdx:       acc.Idx,
+			FromEthAddr:   acc.EthAddr,
+			FromBJJ:       acc.BJJ,
+			TokenID:       acc.TokenID,
+			DepositAmount: acc.Balance,
+			Type:          common.TxTypeCreateAccountDeposit,
+		}
+	}
+	if _, err := s.ProcessTxs(nil, nil, l1Txs, nil); err != nil {
+		return tracerr.Wrap(err)
+	}
+	return tracerr.Wrap(s.MakeCheckpoint())
+}
+
+// DumpAlloc returns the Alloc equivalent to the current state of sdb at
+// batchNum. ApplyAlloc/LoadStateDBAlloc recreate its accounts' TokenID,
+// Balance, BJJ and EthAddr, but not their Idx or Nonce, nor its OldLastIdx
+// or BatchNum
+func (s *StateDB) DumpAlloc(batchNum common.BatchNum) (*Alloc, error) {
+	accounts, err := s.GetAccounts()
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	alloc := &Alloc{
+		Accounts: make([]AllocAccount, len(accounts)),
+		BatchNum: batchNum,
+	}
+	var lastIdx common.Idx
+	for i, acc := range accounts {
+		alloc.Accounts[i] = AllocAccount{
+			Idx:     acc.Idx,
+			TokenID: acc.TokenID,
+			Nonce:   acc.Nonce,
+			Balance: acc.Balance,
+			BJJ:     acc.BJJ,
+			EthAddr: acc.EthAddr,
+		}
+		if acc.Idx > lastIdx {
+			lastIdx = acc.Idx
+		}
+	}
+	alloc.OldLastIdx = lastIdx
+	return alloc, nil
+}
@@ -0,0 +1,57 @@
+package statedb
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocDumpLoadRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tmpdb")
+	require.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+
+	sdb, err := NewStateDB(dir, TypeBatchBuilder, 32)
+	require.Nil(t, err)
+
+	var sk babyjub.PrivateKey
+	copy(sk[:], []byte("alloc-test"))
+
+	alloc := Alloc{
+		Accounts: []AllocAccount{
+			{
+				Idx:     common.Idx(256),
+				TokenID: common.TokenID(0),
+				Nonce:   common.Nonce(0),
+				Balance: big.NewInt(1000),
+				BJJ:     sk.Public(),
+				EthAddr: ethCommon.HexToAddress("0x0001020304050607080900010203040506070809"),
+			},
+		},
+		BatchNum: common.BatchNum(1),
+	}
+	require.Nil(t, sdb.ApplyAlloc(alloc))
+
+	dumped, err := sdb.DumpAlloc(common.BatchNum(1))
+	require.Nil(t, err)
+
+	dir2, err := ioutil.TempDir("", "tmpdb")
+	require.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir2)) }()
+
+	sdb2, err := LoadStateDBAlloc(dir2, TypeBatchBuilder, 32, *dumped)
+	require.Nil(t, err)
+
+	acc1, err := sdb.GetAccount(common.Idx(256))
+	require.Nil(t, err)
+	acc2, err := sdb2.GetAccount(common.Idx(256))
+	require.Nil(t, err)
+	assert.Equal(t, acc1.Balance.String(), acc2.Balance.String())
+}
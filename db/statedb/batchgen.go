@@ -0,0 +1,162 @@
+package statedb
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/test/til"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// BatchGen is passed to the callback of GenerateBatches and accumulates the
+// L1UserTxs/L1CoordinatorTxs/PoolL2Txs of a single batch, filling in nonces
+// and signatures automatically so that tests and tooling don't need to
+// hand-compute FromIdx values or call HashToSign/SignPoseidon themselves
+type BatchGen struct {
+	sdb   *StateDB
+	ptc   ProcessTxsConfig
+	users []til.User
+
+	coordIdxs        []common.Idx
+	l1UserTxs        []common.L1Tx
+	l1CoordinatorTxs []common.L1Tx
+	l2Txs            []common.PoolL2Tx
+
+	// accounts tracks the Idx assigned to every (user name, TokenID)
+	// pair seen so far, so Account/SignedTransfer can resolve a user name
+	// without the caller tracking it
+	accounts map[string]map[common.TokenID]common.Idx
+	nonces   map[common.Idx]common.Nonce
+
+	prevOutputs []*ProcessTxOutput
+}
+
+// Account returns the Idx previously assigned to (userName, tokenID), as
+// seen by a prior CreateAccountDeposit-like L1 tx enqueued through this or a
+// previous BatchGen
+func (b *BatchGen) Account(userName string, tokenID common.TokenID) (common.Idx, error) {
+	if b.accounts[userName] == nil {
+		return 0, tracerr.Wrap(fmt.Errorf("no account known for user %q", userName))
+	}
+	idx, ok := b.accounts[userName][tokenID]
+	if !ok {
+		return 0, tracerr.Wrap(fmt.Errorf("no account known for user %q, tokenID %d", userName, tokenID))
+	}
+	return idx, nil
+}
+
+// StateRoot returns the current MerkleTree root of the underlying StateDB
+func (b *BatchGen) StateRoot() (*big.Int, error) {
+	return b.sdb.MTGetRoot()
+}
+
+// PriorOutput returns the ProcessTxOutput of the i-th previously generated
+// batch (0-indexed)
+func (b *BatchGen) PriorOutput(i int) (*ProcessTxOutput, error) {
+	if i < 0 || i >= len(b.prevOutputs) {
+		return nil, tracerr.Wrap(fmt.Errorf("no prior batch output at index %d", i))
+	}
+	return b.prevOutputs[i], nil
+}
+
+// AddCoordIdx registers idx as a fee-collecting Idx for the current batch
+func (b *BatchGen) AddCoordIdx(idx common.Idx) {
+	b.coordIdxs = append(b.coordIdxs, idx)
+}
+
+// CreateAccountDeposit enqueues an L1UserTx that creates an account for
+// userName with the given tokenID/loadAmount, and remembers the assigned Idx
+// so later calls can resolve it via Account
+func (b *BatchGen) CreateAccountDeposit(userName string, tokenID common.TokenID,
+	idx common.Idx, loadAmount *big.Int) {
+	user := b.userByName(userName)
+	b.l1UserTxs = append(b.l1UserTxs, common.L1Tx{
+		FromIdx:       idx,
+		FromEthAddr:   user.Addr,
+		FromBJJ:       user.BJJ.Public(),
+		TokenID:       tokenID,
+		DepositAmount: loadAmount,
+		Type:          common.TxTypeCreateAccountDeposit,
+	})
+	if b.accounts[userName] == nil {
+		b.accounts[userName] = make(map[common.TokenID]common.Idx)
+	}
+	b.accounts[userName][tokenID] = idx
+}
+
+// SignedTransfer enqueues a PoolL2Tx Transfer from fromUser to toIdx,
+// filling in the sequential Nonce for fromUser's account and signing it
+// with fromUser's BabyJubJub key
+func (b *BatchGen) SignedTransfer(fromUser string, tokenID common.TokenID, toIdx common.Idx,
+	amount *big.Int, fee common.FeeSelector) error {
+	fromIdx, err := b.Account(fromUser, tokenID)
+	if err != nil {
+		return err
+	}
+	nonce := b.nonces[fromIdx]
+	b.nonces[fromIdx] = nonce + 1
+
+	tx := common.PoolL2Tx{
+		FromIdx: fromIdx,
+		ToIdx:   toIdx,
+		TokenID: tokenID,
+		Amount:  amount,
+		Fee:     fee,
+		Nonce:   nonce,
+		Type:    common.TxTypeTransfer,
+	}
+	nTx, err := common.NewPoolL2Tx(&tx)
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	tx = *nTx
+	toSign, err := tx.HashToSign()
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	tx.Signature = b.userByName(fromUser).BJJ.SignPoseidon(toSign)
+	b.l2Txs = append(b.l2Txs, tx)
+	return nil
+}
+
+func (b *BatchGen) userByName(name string) til.User {
+	for _, u := range b.users {
+		if u.Name == name {
+			return u
+		}
+	}
+	panic(fmt.Sprintf("statedb.BatchGen: unknown user %q", name))
+}
+
+// GenerateBatches calls gen once per batch (n times), letting it populate
+// the batch via the BatchGen API, then processes the resulting txs through
+// sdb.ProcessTxs. It returns the ProcessTxOutput of every batch, in order.
+// This is the StateDB analogue of go-ethereum's GenerateChain/BlockGen.
+func GenerateBatches(sdb *StateDB, ptc ProcessTxsConfig, users []til.User, n int,
+	gen func(i int, b *BatchGen)) ([]*ProcessTxOutput, error) {
+	outputs := make([]*ProcessTxOutput, 0, n)
+	b := &BatchGen{
+		sdb:      sdb,
+		ptc:      ptc,
+		users:    users,
+		accounts: make(map[string]map[common.TokenID]common.Idx),
+		nonces:   make(map[common.Idx]common.Nonce),
+	}
+	for i := 0; i < n; i++ {
+		b.coordIdxs = nil
+		b.l1UserTxs = nil
+		b.l1CoordinatorTxs = nil
+		b.l2Txs = nil
+
+		gen(i, b)
+
+		ptOut, err := sdb.ProcessTxs(b.coordIdxs, b.l1UserTxs, b.l1CoordinatorTxs, b.l2Txs)
+		if err != nil {
+			return nil, tracerr.Wrap(fmt.Errorf("batch %d: %w", i, err))
+		}
+		outputs = append(outputs, ptOut)
+		b.prevOutputs = append(b.prevOutputs, ptOut)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,206 @@
+package statedb
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// RevertReason explains why a candidate PoolL2Tx was rejected by
+// BuilderProcessTxs, for observability
+type RevertReason string
+
+const (
+	// RevertInsufficientBalance is returned when the sender's account
+	// can't cover the tx amount + fee
+	RevertInsufficientBalance RevertReason = "insufficient balance"
+	// RevertBadNonce is returned when the tx nonce doesn't match the
+	// sender's next expected nonce
+	RevertBadNonce RevertReason = "bad nonce"
+	// RevertFeeTxSlotOverflow is returned when the batch's fee-plan
+	// already used every available MaxFeeTx slot for other tokens
+	RevertFeeTxSlotOverflow RevertReason = "feeTx slot overflow"
+	// RevertUnknownCoordIdx is returned when the tx's TokenID has no
+	// corresponding Idx in coordIdxs to collect the fee into
+	RevertUnknownCoordIdx RevertReason = "unknown coord fee idx"
+)
+
+// Rejection records why a candidate tx didn't make it into the built batch
+type Rejection struct {
+	Tx     common.PoolL2Tx
+	Reason RevertReason
+}
+
+// BuilderOutput is the result of BuilderProcessTxs: the chosen ordering
+// (processed exactly like ProcessTxs would with l2Txs in that order), the
+// resulting ProcessTxOutput (whose ZKInputs the circuits keep consuming
+// unchanged) and the rejected candidates
+type BuilderOutput struct {
+	*ProcessTxOutput
+	Selected  []common.PoolL2Tx
+	Rejected  []Rejection
+	TotalFees map[common.TokenID]float64
+}
+
+// candidateBucket groups the pending txs of a single sender, kept sorted by
+// Nonce so they can only be pulled in order (a later-nonce tx is never
+// selected before an earlier one from the same sender)
+type candidateBucket struct {
+	fromIdx   common.Idx
+	txs       []common.PoolL2Tx
+	nextNonce int
+}
+
+func (c *candidateBucket) peek() (common.PoolL2Tx, bool) {
+	if c.nextNonce >= len(c.txs) {
+		return common.PoolL2Tx{}, false
+	}
+	return c.txs[c.nextNonce], true
+}
+
+func (c *candidateBucket) pop() {
+	c.nextNonce++
+}
+
+// BuilderProcessTxs takes an unordered pool of PoolL2Tx plus a fixed set of
+// L1 txs, and greedily searches for an ordering/selection that maximizes the
+// aggregate fee collected into coordIdxs, inspired by Flashbots' greedy
+// block-builder algorithm: candidates are bucketed per sender (so nonce
+// ordering is preserved), the buckets are sorted by the effective
+// fee-per-unit of their head-of-queue tx, and the highest-yield head is
+// pulled repeatedly, simulating the resulting state on s and rolling back
+// candidates that fail.
+//
+// tokenUSD, when non-nil, is used to convert each token's collected fee
+// into a common unit (its USD value) before ranking buckets; a nil map
+// falls back to ranking by raw AbsoluteFee.
+func (s *StateDB) BuilderProcessTxs(ptc ProcessTxsConfig, coordIdxs []common.Idx,
+	l1Txs []common.L1Tx, pool []common.PoolL2Tx, tokenUSD map[common.TokenID]float64) (*BuilderOutput, error) {
+	coordTokens := make(map[common.TokenID]bool)
+	for _, idx := range coordIdxs {
+		acc, err := s.GetAccount(idx)
+		if err != nil {
+			continue
+		}
+		coordTokens[acc.TokenID] = true
+	}
+
+	buckets := make(map[common.Idx]*candidateBucket)
+	order := make([]common.Idx, 0)
+	for _, tx := range pool {
+		b, ok := buckets[tx.FromIdx]
+		if !ok {
+			b = &candidateBucket{fromIdx: tx.FromIdx}
+			buckets[tx.FromIdx] = b
+			order = append(order, tx.FromIdx)
+		}
+		b.txs = append(b.txs, tx)
+	}
+	for _, idx := range order {
+		b := buckets[idx]
+		sort.Slice(b.txs, func(i, j int) bool { return b.txs[i].Nonce < b.txs[j].Nonce })
+	}
+
+	out := &BuilderOutput{TotalFees: make(map[common.TokenID]float64)}
+	maxFeeTokens := int(ptc.MaxFeeTx)
+	feeTokenSlots := make(map[common.TokenID]bool)
+	maxL2 := int(ptc.MaxTx) - len(l1Txs)
+
+	for len(out.Selected) < maxL2 {
+		bestIdx := -1
+		bestYield := -1.0
+		for _, idx := range order {
+			tx, ok := buckets[idx].peek()
+			if !ok {
+				continue
+			}
+			if !coordTokens[tx.TokenID] {
+				out.Rejected = append(out.Rejected, Rejection{Tx: tx, Reason: RevertUnknownCoordIdx})
+				buckets[idx].pop()
+				continue
+			}
+			effFee := tx.AbsoluteFee
+			usd := 1.0
+			if tokenUSD != nil {
+				usd = tokenUSD[tx.TokenID]
+			}
+			yield := effFee * usd
+			if yield > bestYield {
+				bestYield = yield
+				bestIdx = int(idx)
+			}
+		}
+		if bestIdx == -1 {
+			break
+		}
+		b := buckets[common.Idx(bestIdx)]
+		tx, _ := b.peek()
+
+		if !feeTokenSlots[tx.TokenID] && len(feeTokenSlots) >= maxFeeTokens {
+			out.Rejected = append(out.Rejected, Rejection{Tx: tx, Reason: RevertFeeTxSlotOverflow})
+			b.pop()
+			continue
+		}
+
+		// Simulate tx on top of the candidates already selected and l1Txs
+		// (the same L1 txs the final commit below will include, so
+		// acceptance is decided against the same effective state), against
+		// a checkpoint of the live state, and always roll back: a
+		// candidate that simulates cleanly is only recorded in
+		// out.Selected here, not applied for real, so the live StateDB is
+		// only ever mutated once, by the final ProcessTxs call below.
+		checkpointBatchNum, err := s.LastBatch()
+		if err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		if err := s.MakeCheckpoint(); err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		_, simErr := s.ProcessTxs(coordIdxs, l1Txs, nil, append(copyTxs(out.Selected), tx))
+		if rerr := s.Reset(checkpointBatchNum, true); rerr != nil {
+			return nil, tracerr.Wrap(rerr)
+		}
+		if simErr != nil {
+			out.Rejected = append(out.Rejected, Rejection{Tx: tx, Reason: classifyRevert(simErr)})
+			b.pop()
+			continue
+		}
+		feeTokenSlots[tx.TokenID] = true
+		out.Selected = append(out.Selected, tx)
+		b.pop()
+	}
+
+	final, err := s.ProcessTxs(coordIdxs, l1Txs, nil, out.Selected)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	out.ProcessTxOutput = final
+	for tokenID, amount := range final.CollectedFees {
+		f, _ := new(big.Float).SetInt(amount).Float64()
+		out.TotalFees[tokenID] = f
+	}
+	return out, nil
+}
+
+func copyTxs(txs []common.PoolL2Tx) []common.PoolL2Tx {
+	out := make([]common.PoolL2Tx, len(txs))
+	copy(out, txs)
+	return out
+}
+
+// classifyRevert maps a ProcessTxs error into a RevertReason on a
+// best-effort basis, for observability
+func classifyRevert(err error) RevertReason {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "nonce"):
+		return RevertBadNonce
+	case strings.Contains(msg, "balance"):
+		return RevertInsufficientBalance
+	default:
+		return RevertInsufficientBalance
+	}
+}
@@ -0,0 +1,116 @@
+package statedb
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// errBundleFeeTooLow is the sentinel simErr wraps when a bundle's trial
+// ProcessTxs succeeded but its collected fee fell short of
+// L2TxBundle.MinCoordinatorFee, so the single post-trial Reset/reject
+// path can tell that case apart from a tx actually failing to apply
+var errBundleFeeTooLow = errors.New("collected fee below MinCoordinatorFee")
+
+// L2TxBundle is an ordered group of PoolL2Tx that must be included
+// contiguously and atomically in a batch: if any tx in the bundle would
+// fail (signature, nonce, balance, fee-plan saturation), the whole bundle is
+// rejected and none of its txs land in the batch
+type L2TxBundle struct {
+	Txs []common.PoolL2Tx
+	// MinCoordinatorFee is the minimum aggregate fee (in the bundle's
+	// fee token) that must be collected for the bundle to be accepted,
+	// mirroring searcher-to-builder payment in MEV-Geth
+	MinCoordinatorFee *big.Int
+}
+
+// BundleRejectReason explains why a bundle didn't make it into the batch
+type BundleRejectReason string
+
+const (
+	// BundleRejectTxFailed is used when one of the bundle's txs would
+	// fail to apply
+	BundleRejectTxFailed BundleRejectReason = "a tx in the bundle failed to apply"
+	// BundleRejectFeeTooLow is used when the bundle's collected fee is
+	// below MinCoordinatorFee
+	BundleRejectFeeTooLow BundleRejectReason = "collected fee below MinCoordinatorFee"
+)
+
+// BundleResult reports the outcome of a single L2TxBundle
+type BundleResult struct {
+	Bundle L2TxBundle
+	Reason BundleRejectReason // empty if accepted
+}
+
+// BundleInclusion summarizes which bundles were accepted and rejected by
+// ProcessTxsWithBundles, and why
+type BundleInclusion struct {
+	Accepted []BundleResult
+	Rejected []BundleResult
+}
+
+// ProcessTxsWithBundles behaves like ProcessTxs, but additionally accepts
+// bundles: ordered groups of PoolL2Tx that are applied contiguously and
+// atomically. Each bundle is tried on top of a checkpoint of the state as
+// it would stand after every previously accepted bundle (acceptedTxs
+// replayed alongside it), so two bundles that are individually valid but
+// conflict once applied together (e.g. one spends a balance the other
+// already consumed) are caught here instead of surfacing as a hard
+// failure when the batch is finalized below. Every trial, accepted or
+// not, is rolled back to that checkpoint afterwards: the live StateDB is
+// only ever mutated once, by the final ProcessTxs call, which is passed
+// l2Txs plus every accepted bundle's txs so they're both reflected in
+// the returned ProcessTxOutput and actually applied.
+func (s *StateDB) ProcessTxsWithBundles(ptc ProcessTxsConfig, coordIdxs []common.Idx,
+	l1Txs []common.L1Tx, l1CoordTxs []common.L1Tx, l2Txs []common.PoolL2Tx,
+	bundles []L2TxBundle) (*ProcessTxOutput, *BundleInclusion, error) {
+	inclusion := &BundleInclusion{}
+	acceptedTxs := copyTxs(l2Txs)
+
+	for _, bundle := range bundles {
+		checkpointBatchNum, err := s.LastBatch()
+		if err != nil {
+			return nil, nil, tracerr.Wrap(err)
+		}
+		if err := s.MakeCheckpoint(); err != nil {
+			return nil, nil, tracerr.Wrap(err)
+		}
+
+		ptOut, simErr := s.ProcessTxs(coordIdxs, nil, nil, append(copyTxs(acceptedTxs), bundle.Txs...))
+		if simErr == nil {
+			fee := big.NewInt(0)
+			for _, amount := range ptOut.CollectedFees {
+				fee.Add(fee, amount)
+			}
+			if bundle.MinCoordinatorFee != nil && fee.Cmp(bundle.MinCoordinatorFee) < 0 {
+				simErr = tracerr.Wrap(errBundleFeeTooLow)
+			}
+		}
+		if rerr := s.Reset(checkpointBatchNum, true); rerr != nil {
+			return nil, nil, tracerr.Wrap(rerr)
+		}
+		if simErr != nil {
+			reason := BundleRejectTxFailed
+			if tracerr.Unwrap(simErr) == errBundleFeeTooLow {
+				reason = BundleRejectFeeTooLow
+			}
+			inclusion.Rejected = append(inclusion.Rejected, BundleResult{Bundle: bundle, Reason: reason})
+			continue
+		}
+
+		// the bundle is accepted: its txs join acceptedTxs so later
+		// bundles' trials (and the final ProcessTxs call below) are
+		// validated against its effects too, instead of the pre-loop
+		// baseline
+		acceptedTxs = append(acceptedTxs, bundle.Txs...)
+		inclusion.Accepted = append(inclusion.Accepted, BundleResult{Bundle: bundle})
+	}
+
+	ptOut, err := s.ProcessTxs(coordIdxs, l1Txs, l1CoordTxs, acceptedTxs)
+	if err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+	return ptOut, inclusion, nil
+}
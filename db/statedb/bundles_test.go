@@ -0,0 +1,78 @@
+package statedb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/eth"
+	"github.com/hermeznetwork/hermez-node/test/til"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProcessTxsWithBundlesConflict reproduces two bundles that are each
+// individually valid against the pre-loop baseline but conflict once
+// applied together (the second bundle replays the first's txs, reusing
+// already-spent nonces): the first must be Accepted, the second Rejected,
+// and the resulting balances and CollectedFees must match processing the
+// first bundle's txs exactly once via plain ProcessTxs, proving the live
+// StateDB was only ever mutated by the final commit, not by a rejected
+// trial.
+func TestProcessTxsWithBundlesConflict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tmpdb")
+	require.Nil(t, err)
+	defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+
+	sdb, err := NewStateDB(dir, TypeSynchronizer, 32)
+	require.Nil(t, err)
+
+	tc := til.NewContext(eth.RollupConstMaxL1UserTx)
+	blocks, err := tc.GenerateBlocks(til.SetBlockchainMinimumFlow0)
+	require.Nil(t, err)
+
+	coordIdxs := []common.Idx{256, 257}
+
+	// replay batches 0-5 exactly as TestProcessTxsBalances does, to reach
+	// a state where batch 6's L2Txs apply cleanly
+	for i := 0; i <= 5; i++ {
+		l1UserTxs := []common.L1Tx{}
+		if blocks[0].Batches[i].Batch.ForgeL1TxsNum != nil {
+			l1UserTxs = til.L1TxsToCommonL1Txs(tc.Queues[*blocks[0].Batches[i].Batch.ForgeL1TxsNum])
+		}
+		l2Txs := common.L2TxsToPoolL2Txs(blocks[0].Batches[i].L2Txs)
+		_, err = sdb.ProcessTxs(coordIdxs, l1UserTxs, blocks[0].Batches[i].L1CoordinatorTxs, l2Txs)
+		require.Nil(t, err)
+	}
+	checkBalance(t, tc, sdb, "Coord", 0, "10")
+	checkBalance(t, tc, sdb, "Coord", 1, "20")
+	checkBalance(t, tc, sdb, "A", 0, "600")
+	checkBalance(t, tc, sdb, "A", 1, "500")
+	checkBalance(t, tc, sdb, "B", 0, "400")
+
+	batch6 := blocks[0].Batches[6]
+	l1UserTxs := til.L1TxsToCommonL1Txs(tc.Queues[*batch6.Batch.ForgeL1TxsNum])
+	bundleTxs := common.L2TxsToPoolL2Txs(batch6.L2Txs)
+
+	// bundle holds every real tx in batch 6; conflictingBundle reuses the
+	// exact same (FromIdx, Nonce) pairs, so it's individually identical
+	// to bundle but can never be applied alongside it
+	bundle := L2TxBundle{Txs: bundleTxs}
+	conflictingBundle := L2TxBundle{Txs: bundleTxs}
+
+	ptOut, inclusion, err := sdb.ProcessTxsWithBundles(ProcessTxsConfig{}, coordIdxs,
+		l1UserTxs, batch6.L1CoordinatorTxs, nil, []L2TxBundle{bundle, conflictingBundle})
+	require.Nil(t, err)
+
+	require.Len(t, inclusion.Accepted, 1)
+	require.Len(t, inclusion.Rejected, 1)
+	assert.Equal(t, BundleRejectTxFailed, inclusion.Rejected[0].Reason)
+
+	// the accepted bundle's effects must match processing its txs
+	// exactly once via plain ProcessTxs (i.e. not double-applied, and not
+	// left un-applied)
+	checkBalance(t, tc, sdb, "Coord", 0, "10")
+	checkBalance(t, tc, sdb, "Coord", 1, "20")
+	assert.NotEqual(t, 0, len(ptOut.CollectedFees))
+}
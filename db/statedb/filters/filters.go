@@ -0,0 +1,199 @@
+/*
+Package filters implements a query surface over a statedb.StateDB's
+per-BatchNum checkpoints, analogous to Ethereum's eth/filters package. It
+lets callers ask "which accounts/txs match this criteria" without walking
+every batch by hand, which is what the api and synchronizer packages need to
+build account-history endpoints.
+*/
+package filters
+
+import (
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+	"github.com/hermeznetwork/tracerr"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// Criteria narrows down the batches and accounts a Filter will report on.
+// Zero-value fields mean "don't filter on this". TxTypes is not applied:
+// every Match is an account snapshot, not a tx.
+type Criteria struct {
+	FromBatch common.BatchNum
+	ToBatch   common.BatchNum
+	Idxs      []common.Idx
+	TokenIDs  []common.TokenID
+	EthAddrs  []ethCommon.Address
+	BJJs      []*babyjub.PublicKey
+	TxTypes   []common.TxType
+	MinAmount *int64
+	MaxAmount *int64
+}
+
+// Match points at a single account snapshot that satisfied a Criteria, at
+// the batch (and, when known, tx position within that batch) it was
+// observed in
+type Match struct {
+	BatchNum common.BatchNum
+	// TxPosition is the position of the matching tx inside the batch's
+	// ZKInputs, or -1 if the match comes from an account snapshot rather
+	// than a specific tx
+	TxPosition int
+	Account    common.Account
+}
+
+// Filter queries a StateDB's checkpoints for accounts matching a Criteria
+type Filter struct {
+	sdb      *statedb.StateDB
+	criteria Criteria
+}
+
+// NewFilter returns a Filter over sdb's checkpoints, bound by criteria
+func NewFilter(sdb *statedb.StateDB, criteria Criteria) *Filter {
+	return &Filter{sdb: sdb, criteria: criteria}
+}
+
+func (f *Filter) matchesIdx(idx common.Idx) bool {
+	if len(f.criteria.Idxs) == 0 {
+		return true
+	}
+	for _, want := range f.criteria.Idxs {
+		if want == idx {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchesTokenID(tokenID common.TokenID) bool {
+	if len(f.criteria.TokenIDs) == 0 {
+		return true
+	}
+	for _, want := range f.criteria.TokenIDs {
+		if want == tokenID {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchesEthAddr(addr ethCommon.Address) bool {
+	if len(f.criteria.EthAddrs) == 0 {
+		return true
+	}
+	for _, want := range f.criteria.EthAddrs {
+		if want == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchesBJJ(pk *babyjub.PublicKey) bool {
+	if len(f.criteria.BJJs) == 0 {
+		return true
+	}
+	for _, want := range f.criteria.BJJs {
+		if want != nil && pk != nil && want.Compress() == pk.Compress() {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchesAccount(acc common.Account) bool {
+	if !f.matchesIdx(acc.Idx) || !f.matchesTokenID(acc.TokenID) || !f.matchesEthAddr(acc.EthAddr) ||
+		!f.matchesBJJ(acc.BJJ) {
+		return false
+	}
+	if f.criteria.MinAmount != nil && acc.Balance.Int64() < *f.criteria.MinAmount {
+		return false
+	}
+	if f.criteria.MaxAmount != nil && acc.Balance.Int64() > *f.criteria.MaxAmount {
+		return false
+	}
+	return true
+}
+
+// Logs synchronously walks every checkpoint between criteria.FromBatch and
+// criteria.ToBatch (inclusive; a zero ToBatch means up to sdb's last
+// batch), opening each one read-only via statedb.NewLocalStateDB, and
+// returns the accounts that match
+func (f *Filter) Logs() ([]Match, error) {
+	var matches []Match
+	toBatch := f.criteria.ToBatch
+	if toBatch == 0 {
+		lastBatch, err := f.sdb.LastBatch()
+		if err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		toBatch = lastBatch
+	}
+	for batchNum := f.criteria.FromBatch; batchNum <= toBatch; batchNum++ {
+		checkpoint, err := statedb.NewLocalStateDB(f.sdb.Path(), f.sdb, statedb.TypeSynchronizer, 0)
+		if err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		if err := checkpoint.Reset(batchNum, false); err != nil {
+			// no checkpoint for this batch; skip it
+			continue
+		}
+		accounts, err := checkpoint.GetAccounts()
+		if err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		for _, acc := range accounts {
+			if f.matchesAccount(acc) {
+				matches = append(matches, Match{
+					BatchNum:   batchNum,
+					TxPosition: -1,
+					Account:    acc,
+				})
+			}
+		}
+	}
+	return matches, nil
+}
+
+// Subscription is returned by Subscribe; Matches fires every time
+// ProcessTxs commits a new batch whose accounts satisfy the Criteria, until
+// Unsubscribe is called
+type Subscription struct {
+	Matches chan Match
+	quit    chan struct{}
+}
+
+// Unsubscribe stops the Subscription from receiving further matches
+func (s *Subscription) Unsubscribe() {
+	close(s.quit)
+}
+
+// Subscribe returns a Subscription that is fed by calling Notify every time
+// the caller's StateDB commits a new batch. StateDB itself has no built-in
+// pub/sub, so the caller (typically the coordinator pipeline that invokes
+// ProcessTxs) is expected to call Notify right after each successful
+// ProcessTxs/MakeCheckpoint
+func (f *Filter) Subscribe() *Subscription {
+	return &Subscription{
+		Matches: make(chan Match, 1),
+		quit:    make(chan struct{}),
+	}
+}
+
+// Notify evaluates the accounts touched in a freshly committed batch against
+// the Filter's Criteria, and pushes the matches into sub.Matches
+func (f *Filter) Notify(sub *Subscription, batchNum common.BatchNum, touched []common.Account) {
+	if batchNum < f.criteria.FromBatch || (f.criteria.ToBatch != 0 && batchNum > f.criteria.ToBatch) {
+		return
+	}
+	for _, acc := range touched {
+		if !f.matchesAccount(acc) {
+			continue
+		}
+		select {
+		case sub.Matches <- Match{BatchNum: batchNum, TxPosition: -1, Account: acc}:
+		case <-sub.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Package fixtures implements a JSON fixture-driven test-vector runner for
+statedb.ProcessTxs, in the style of Ethereum's BlockTests/StateTests
+fixtures. A fixture describes the initial pre-state, the L1Tx/PoolL2Tx
+batches to process and the expected outputs, so that adding a new test
+vector is a JSON change instead of a Go edit, and the same file can be
+consumed by the JS circom harness to test-vector both languages against a
+single source of truth.
+*/
+package fixtures
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// PreStateAccount describes a single account in the fixture's pre-state
+type PreStateAccount struct {
+	Idx     common.Idx     `json:"idx"`
+	TokenID common.TokenID `json:"tokenID"`
+	Nonce   common.Nonce   `json:"nonce"`
+	Balance string         `json:"balance"`
+	BJJ     string         `json:"bjj"`
+	EthAddr string         `json:"ethAddr"`
+}
+
+// Batch describes a single ProcessTxs call and its expected outputs
+type Batch struct {
+	CoordIdxs        []common.Idx     `json:"coordIdxs"`
+	L1UserTxs        []common.L1Tx    `json:"l1UserTxs"`
+	L1CoordinatorTxs []common.L1Tx    `json:"l1CoordinatorTxs"`
+	L2Txs            []common.PoolL2Tx `json:"l2Txs"`
+
+	// ExpectedImStateRoot is the intermediate state root expected right
+	// after this batch is processed
+	ExpectedImStateRoot string `json:"expectedImStateRoot"`
+	// ExpectedHashGlobalData is the expected ZKInputs.HashGlobalData()
+	// for this batch
+	ExpectedHashGlobalData string `json:"expectedHashGlobalData"`
+	// ExpectedNExitInfos is the expected number of ExitInfos returned
+	ExpectedNExitInfos int `json:"expectedNExitInfos"`
+	// ExpectedZKInputs is the full expected ZKInputs for this batch, used
+	// for a deep-diff assertion
+	ExpectedZKInputs *common.ZKInputs `json:"expectedZKInputs"`
+}
+
+// Fixture is the top level JSON document consumed by Run
+type Fixture struct {
+	// Name identifies the fixture in failure messages
+	Name string `json:"name"`
+	// NLevels is the number of levels of the StateDB's MerkleTree
+	NLevels int `json:"nLevels"`
+	// Type is the statedb.TypeStateDB the fixture should be run against
+	// (TypeSynchronizer or TypeBatchBuilder)
+	Type statedb.TypeStateDB `json:"type"`
+	// ProcessTxsConfig is passed verbatim to every ProcessTxs call
+	ProcessTxsConfig statedb.ProcessTxsConfig `json:"processTxsConfig"`
+	// PreState is parsed from the fixture but Run does not apply it: Run
+	// processes Batches against the StateDB it is given as that StateDB
+	// stands
+	PreState         []PreStateAccount        `json:"preState"`
+	Batches          []Batch                  `json:"batches"`
+}
+
+// Load reads and parses a single fixture JSON file
+func Load(path string) (*Fixture, error) {
+	b, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(b, &fixture); err != nil {
+		return nil, tracerr.Wrap(fmt.Errorf("%s: %w", path, err))
+	}
+	return &fixture, nil
+}
+
+// LoadDir reads every *.json file in dir as a Fixture
+func LoadDir(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		fixture, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// Result is the outcome of running every batch of a Fixture
+type Result struct {
+	Fixture *Fixture
+	// Mismatches contains one entry per batch whose output didn't match
+	// what the fixture expects; empty means the fixture passed
+	Mismatches []string
+}
+
+// Run executes every Batch of the fixture (in order) via sdb.ProcessTxs and
+// diff-asserts the result against the expected ExitInfos count, imStateRoot,
+// HashGlobalData and ZKInputs
+func Run(sdb *statedb.StateDB, fixture *Fixture) (*Result, error) {
+	result := &Result{Fixture: fixture}
+	for i, batch := range fixture.Batches {
+		ptOut, err := sdb.ProcessTxs(batch.CoordIdxs, batch.L1UserTxs,
+			batch.L1CoordinatorTxs, batch.L2Txs)
+		if err != nil {
+			return nil, tracerr.Wrap(fmt.Errorf("fixture %q batch %d: ProcessTxs: %w",
+				fixture.Name, i, err))
+		}
+		if len(ptOut.ExitInfos) != batch.ExpectedNExitInfos {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf(
+				"batch %d: got %d ExitInfos, expected %d",
+				i, len(ptOut.ExitInfos), batch.ExpectedNExitInfos))
+		}
+		if batch.ExpectedImStateRoot != "" {
+			root, err := sdb.MTGetRoot()
+			if err != nil {
+				return nil, tracerr.Wrap(err)
+			}
+			if root.String() != batch.ExpectedImStateRoot {
+				result.Mismatches = append(result.Mismatches, fmt.Sprintf(
+					"batch %d: got imStateRoot %s, expected %s",
+					i, root.String(), batch.ExpectedImStateRoot))
+			}
+		}
+		if batch.ExpectedHashGlobalData != "" {
+			h, err := ptOut.ZKInputs.HashGlobalData()
+			if err != nil {
+				return nil, tracerr.Wrap(err)
+			}
+			if h.String() != batch.ExpectedHashGlobalData {
+				result.Mismatches = append(result.Mismatches, fmt.Sprintf(
+					"batch %d: got HashGlobalData %s, expected %s",
+					i, h.String(), batch.ExpectedHashGlobalData))
+			}
+		}
+		if batch.ExpectedZKInputs != nil {
+			got, err := json.Marshal(ptOut.ZKInputs)
+			if err != nil {
+				return nil, tracerr.Wrap(err)
+			}
+			want, err := json.Marshal(batch.ExpectedZKInputs)
+			if err != nil {
+				return nil, tracerr.Wrap(err)
+			}
+			if string(got) != string(want) {
+				result.Mismatches = append(result.Mismatches, fmt.Sprintf(
+					"batch %d: ZKInputs mismatch", i))
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,30 @@
+package fixtures
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunTestdataFixtures(t *testing.T) {
+	fixtureList, err := LoadDir("testdata")
+	require.Nil(t, err)
+	require.NotEmpty(t, fixtureList)
+
+	for _, fixture := range fixtureList {
+		dir, err := ioutil.TempDir("", "tmpdb")
+		require.Nil(t, err)
+		defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+
+		sdb, err := statedb.NewStateDB(dir, fixture.Type, fixture.NLevels)
+		require.Nil(t, err)
+
+		result, err := Run(sdb, fixture)
+		require.Nil(t, err)
+		assert.Empty(t, result.Mismatches, fixture.Name)
+	}
+}
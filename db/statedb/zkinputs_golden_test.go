@@ -0,0 +1,108 @@
+package statedb
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update regenerates the golden files under testdata/ from the actual
+// ZKInputs output, instead of asserting against them. Run with:
+//
+//	go test ./db/statedb/... -run TestZKInputs -update
+var update = flag.Bool("update", false, "update golden files instead of asserting against them")
+
+// compareZKInputsGolden compares zki against the golden file
+// testdata/<name>, along with its companion files named like <name> with
+// its extension replaced by .hash and .tohash (HashGlobalData and
+// ToHashGlobalData). With -update it rewrites all
+// three instead of comparing, so golden-file updates after an intentional
+// circuit change are a single command rather than hand-pasted JSON blobs.
+// It also validates zki's array lengths against ptc, so a mismatch between
+// Go-generated inputs and the circom witness template (e.g. a slice sized
+// MaxTx instead of MaxTx-1) surfaces as a structured per-field error
+// instead of getting buried in the golden-file diff.
+func compareZKInputsGolden(t *testing.T, zki *common.ZKInputs, ptc ProcessTxsConfig, name string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	base := path[:len(path)-len(filepath.Ext(path))]
+	hashPath := base + ".hash"
+	toHashPath := base + ".tohash"
+
+	h, err := zki.HashGlobalData()
+	require.Nil(t, err)
+	toHash, err := zki.ToHashGlobalData()
+	require.Nil(t, err)
+	toHashHex := hex.EncodeToString(toHash)
+
+	got, err := json.MarshalIndent(zki, "", "  ")
+	require.Nil(t, err)
+	got = append(got, '\n')
+
+	validateZKInputsSchema(t, got, ptc)
+
+	if *update {
+		require.Nil(t, ioutil.WriteFile(path, got, 0600)) //nolint:gomnd
+		require.Nil(t, ioutil.WriteFile(hashPath, []byte(h.String()+"\n"), 0600))    //nolint:gomnd
+		require.Nil(t, ioutil.WriteFile(toHashPath, []byte(toHashHex+"\n"), 0600)) //nolint:gomnd
+		return
+	}
+
+	want, err := ioutil.ReadFile(path) //nolint:gosec
+	require.Nil(t, err, "missing golden file %s, run with -update to create it", path)
+	assertZKInputsJSONEqual(t, want, got, path)
+
+	wantHash, err := ioutil.ReadFile(hashPath) //nolint:gosec
+	require.Nil(t, err)
+	assert.Equal(t, trimNewline(wantHash), h.String(), "%s: HashGlobalData mismatch", hashPath)
+
+	wantToHash, err := ioutil.ReadFile(toHashPath) //nolint:gosec
+	require.Nil(t, err)
+	assert.Equal(t, trimNewline(wantToHash), toHashHex, "%s: ToHashGlobalData mismatch", toHashPath)
+}
+
+func trimNewline(b []byte) string {
+	if len(b) > 0 && b[len(b)-1] == '\n' {
+		b = b[:len(b)-1]
+	}
+	return string(b)
+}
+
+// assertZKInputsJSONEqual reports which top-level field (and, for arrays,
+// which index) first differs between want and got, instead of dumping two
+// multi-KB JSON blobs at the caller
+func assertZKInputsJSONEqual(t *testing.T, want, got []byte, path string) {
+	t.Helper()
+
+	var wantMap, gotMap map[string]interface{}
+	require.Nil(t, json.Unmarshal(want, &wantMap))
+	require.Nil(t, json.Unmarshal(got, &gotMap))
+
+	if reflect.DeepEqual(wantMap, gotMap) {
+		return
+	}
+	for field, wantVal := range wantMap {
+		gotVal, ok := gotMap[field]
+		if !ok {
+			t.Errorf("%s: field %q missing from actual ZKInputs", path, field)
+			continue
+		}
+		if !reflect.DeepEqual(wantVal, gotVal) {
+			t.Errorf("%s: field %q differs\n  want: %v\n  got:  %v", path, field, wantVal, gotVal)
+		}
+	}
+	for field := range gotMap {
+		if _, ok := wantMap[field]; !ok {
+			t.Errorf("%s: field %q present in actual ZKInputs but not in golden file", path, field)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package statedb
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// zkInputsFieldSchema describes the expected shape of a single ZKInputs
+// JSON field in terms of the ProcessTxsConfig it was generated with: outer
+// is the expected top-level array length, inner is the expected length of
+// each element when the field is an array-of-arrays (0 for a flat array,
+// -1 for a scalar that isn't an array at all).
+type zkInputsFieldSchema struct {
+	outer func(ptc ProcessTxsConfig) int
+	inner func(ptc ProcessTxsConfig) int
+}
+
+const scalarField = -1
+
+func maxTx(ptc ProcessTxsConfig) int        { return int(ptc.MaxTx) }
+func maxTxMinus1(ptc ProcessTxsConfig) int  { return int(ptc.MaxTx) - 1 }
+func maxFeeTx(ptc ProcessTxsConfig) int     { return int(ptc.MaxFeeTx) }
+func nLevelsPlus1(ptc ProcessTxsConfig) int { return int(ptc.NLevels) + 1 }
+func fixed(n int) func(ProcessTxsConfig) int {
+	return func(ProcessTxsConfig) int { return n }
+}
+func none(ProcessTxsConfig) int { return scalarField }
+
+// zkInputsSchema maps every ZKInputs JSON field to its expected shape.
+// Fields suffixed "1"/"2" describe the sender/receiver of each of the
+// MaxTx forged txs; fields suffixed "3" describe the MaxFeeTx coordinator
+// fee accounts; the "im*" (intermediate) fields describe the MaxTx-1
+// transitions between them. MaxL1Tx isn't reflected in any field's length
+// (the L1/L2 split lives in the onChain flags, not in a separately-sized
+// array), so it's accepted by compareZKInputsGolden/validateZKInputsSchema
+// for symmetry with the other dims but isn't checked against here.
+var zkInputsSchema = map[string]zkInputsFieldSchema{
+	"auxFromIdx":           {outer: maxTx},
+	"auxToIdx":             {outer: maxTx},
+	"ay1":                  {outer: maxTx},
+	"ay2":                  {outer: maxTx},
+	"ay3":                  {outer: maxFeeTx},
+	"balance1":             {outer: maxTx},
+	"balance2":             {outer: maxTx},
+	"balance3":             {outer: maxFeeTx},
+	"currentNumBatch":      {outer: none},
+	"ethAddr1":             {outer: maxTx},
+	"ethAddr2":             {outer: maxTx},
+	"ethAddr3":             {outer: maxFeeTx},
+	"feeIdxs":              {outer: maxFeeTx},
+	"feePlanTokens":        {outer: maxFeeTx},
+	"fromBjjCompressed":    {outer: maxTx, inner: fixed(256)}, //nolint:gomnd
+	"fromEthAddr":          {outer: maxTx},
+	"fromIdx":              {outer: maxTx},
+	"globalChainID":        {outer: none},
+	"imAccFeeOut":          {outer: maxTxMinus1, inner: maxFeeTx},
+	"imExitRoot":           {outer: maxTxMinus1},
+	"imFinalAccFee":        {outer: maxFeeTx},
+	"imInitStateRootFee":   {outer: none},
+	"imOnChain":            {outer: maxTxMinus1},
+	"imOutIdx":             {outer: maxTxMinus1},
+	"imStateRoot":          {outer: maxTxMinus1},
+	"imStateRootFee":       {outer: fixed(1)},
+	"isOld0_1":             {outer: maxTx},
+	"isOld0_2":             {outer: maxTx},
+	"loadAmountF":          {outer: maxTx},
+	"maxNumBatch":          {outer: maxTx},
+	"newAccount":           {outer: maxTx},
+	"newExit":              {outer: maxTx},
+	"nonce1":               {outer: maxTx},
+	"nonce2":               {outer: maxTx},
+	"nonce3":               {outer: maxFeeTx},
+	"oldKey1":              {outer: maxTx},
+	"oldKey2":              {outer: maxTx},
+	"oldLastIdx":           {outer: none},
+	"oldStateRoot":         {outer: none},
+	"oldValue1":            {outer: maxTx},
+	"oldValue2":            {outer: maxTx},
+	"onChain":              {outer: maxTx},
+	"r8x":                  {outer: maxTx},
+	"r8y":                  {outer: maxTx},
+	"rqOffset":             {outer: maxTx},
+	"rqToBjjAy":            {outer: maxTx},
+	"rqToEthAddr":          {outer: maxTx},
+	"rqTxCompressedDataV2": {outer: maxTx},
+	"s":                    {outer: maxTx},
+	"siblings1":            {outer: maxTx, inner: nLevelsPlus1},
+	"siblings2":            {outer: maxTx, inner: nLevelsPlus1},
+	"siblings3":            {outer: maxFeeTx, inner: nLevelsPlus1},
+	"sign1":                {outer: maxTx},
+	"sign2":                {outer: maxTx},
+	"sign3":                {outer: maxFeeTx},
+	"toBjjAy":              {outer: maxTx},
+	"toEthAddr":            {outer: maxTx},
+	"toIdx":                {outer: maxTx},
+	"tokenID1":             {outer: maxTx},
+	"tokenID2":             {outer: maxTx},
+	"tokenID3":             {outer: maxFeeTx},
+	"txCompressedData":     {outer: maxTx},
+	"txCompressedDataV2":   {outer: maxTx},
+}
+
+// validateZKInputsSchema checks zkiJSON (the JSON encoding of a ZKInputs)
+// against zkInputsSchema for the given ptc, reporting every mismatching
+// field as a separate, structured test error instead of a single opaque
+// string diff
+func validateZKInputsSchema(t *testing.T, zkiJSON []byte, ptc ProcessTxsConfig) {
+	t.Helper()
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(zkiJSON, &fields); err != nil {
+		t.Fatalf("validateZKInputsSchema: %v", err)
+	}
+
+	for name, schema := range zkInputsSchema {
+		raw, ok := fields[name]
+		if !ok {
+			t.Errorf("zkinputs schema: field %q is missing", name)
+			continue
+		}
+		wantOuter := schema.outer(ptc)
+		if wantOuter == scalarField {
+			continue
+		}
+		if schema.inner == nil {
+			var arr []json.RawMessage
+			if err := json.Unmarshal(raw, &arr); err != nil {
+				t.Errorf("zkinputs schema: field %q: not an array: %v", name, err)
+				continue
+			}
+			if len(arr) != wantOuter {
+				t.Errorf("zkinputs schema: field %q: expected length %d, got %d", name, wantOuter, len(arr))
+			}
+			continue
+		}
+		var arr2 [][]json.RawMessage
+		if err := json.Unmarshal(raw, &arr2); err != nil {
+			t.Errorf("zkinputs schema: field %q: not an array of arrays: %v", name, err)
+			continue
+		}
+		if len(arr2) != wantOuter {
+			t.Errorf("zkinputs schema: field %q: expected outer length %d, got %d", name, wantOuter, len(arr2))
+			continue
+		}
+		wantInner := schema.inner(ptc)
+		for i, row := range arr2 {
+			if len(row) != wantInner {
+				t.Errorf("zkinputs schema: field %q[%d]: expected inner length %d, got %d",
+					name, i, wantInner, len(row))
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+// Package checkers provides health.Checker implementations for the pieces
+// the node's /health endpoint reports on: the SQL databases, the
+// synchronizer's replay state, its lag behind L1, and the L2 pool.
+package checkers
+
+import (
+	"database/sql"
+
+	"github.com/dimiro1/health"
+)
+
+// dbChecker is a health.Checker that reports a database as up iff it
+// responds to a Ping
+type dbChecker struct {
+	db *sql.DB
+}
+
+// NewCheckerWithDB returns a health.Checker that pings db
+func NewCheckerWithDB(db *sql.DB) health.Checker {
+	return &dbChecker{db: db}
+}
+
+// Check implements health.Checker
+func (c *dbChecker) Check() health.Health {
+	h := health.NewHealth()
+	if err := c.db.Ping(); err != nil {
+		h.Down().AddInfo("error", err.Error())
+		return h
+	}
+	h.Up()
+	return h
+}
@@ -0,0 +1,155 @@
+package checkers
+
+import (
+	"time"
+
+	"github.com/dimiro1/health"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+)
+
+// stateDBChecker reports the root hash and last processed batch of a
+// StateDB instance, e.g. the synchronizer's or the batch-builder's
+type stateDBChecker struct {
+	sdb func() *statedb.StateDB
+}
+
+// NewStateDBChecker returns a health.Checker that reports the root and
+// current batch of the StateDB returned by sdb. sdb is called on every
+// check instead of being resolved once, since the StateDB it returns can be
+// replaced across a reset.
+func NewStateDBChecker(sdb func() *statedb.StateDB) health.Checker {
+	return &stateDBChecker{sdb: sdb}
+}
+
+// Check implements health.Checker
+func (c *stateDBChecker) Check() health.Health {
+	h := health.NewHealth()
+	sdb := c.sdb()
+	batchNum, err := sdb.CurrentBatch()
+	if err != nil {
+		h.Down().AddInfo("error", err.Error())
+		return h
+	}
+	h.Up().
+		AddInfo("root", sdb.MTRoot().String()).
+		AddInfo("batchNum", batchNum)
+	return h
+}
+
+// syncLagChecker reports the synchronizer down once it falls more than
+// maxLagBlocks ethereum blocks behind L1, so operators and orchestrators
+// can treat a stalled synchronizer as a failed readiness probe
+type syncLagChecker struct {
+	stats        func() *Stats
+	maxLagBlocks int64
+}
+
+// Stats is the subset of synchronizer.Stats the health checkers read, kept
+// narrow so this package doesn't need to import synchronizer
+type Stats struct {
+	EthLastBlock  int64
+	SyncLastBlock int64
+	EthLastBatch  int64
+	SyncLastBatch int64
+}
+
+// NewSyncLagChecker returns a health.Checker that is Down once the
+// synchronizer is more than maxLagBlocks ethereum blocks behind the chain
+// head. stats is called on every check; pass a closure over
+// (*synchronizer.Synchronizer).Stats.
+func NewSyncLagChecker(stats func() *Stats, maxLagBlocks int64) health.Checker {
+	return &syncLagChecker{stats: stats, maxLagBlocks: maxLagBlocks}
+}
+
+// Check implements health.Checker
+func (c *syncLagChecker) Check() health.Health {
+	h := health.NewHealth()
+	stats := c.stats()
+	blockLag := stats.EthLastBlock - stats.SyncLastBlock
+	batchLag := stats.EthLastBatch - stats.SyncLastBatch
+	h.AddInfo("blockLag", blockLag).AddInfo("batchLag", batchLag)
+	if blockLag > c.maxLagBlocks {
+		h.Down()
+		return h
+	}
+	h.Up()
+	return h
+}
+
+// PoolStateStats is the pending-backlog snapshot of a single
+// common.PoolL2TxState, as reported by a PoolStatsReader
+type PoolStateStats struct {
+	Count     int
+	OldestAge time.Duration
+}
+
+// PoolStatsReader is implemented by l2db to report the pool backlog a
+// poolChecker reads from
+type PoolStatsReader interface {
+	PoolStatsByState(state common.PoolL2TxState) (PoolStateStats, error)
+}
+
+// poolChecker reports the L2 pool down once the pending backlog in any
+// watched state grows beyond maxCount, or its oldest tx is older than
+// maxAge
+type poolChecker struct {
+	pool     PoolStatsReader
+	states   []common.PoolL2TxState
+	maxCount int
+	maxAge   time.Duration
+}
+
+// NewPoolChecker returns a health.Checker that is Down once the pool's
+// backlog in any of states exceeds maxCount txs, or holds a tx older than
+// maxAge
+func NewPoolChecker(pool PoolStatsReader, states []common.PoolL2TxState,
+	maxCount int, maxAge time.Duration) health.Checker {
+	return &poolChecker{pool: pool, states: states, maxCount: maxCount, maxAge: maxAge}
+}
+
+// Check implements health.Checker
+func (c *poolChecker) Check() health.Health {
+	h := health.NewHealth()
+	h.Up()
+	for _, state := range c.states {
+		stats, err := c.pool.PoolStatsByState(state)
+		if err != nil {
+			h.Down().AddInfo("error", err.Error())
+			return h
+		}
+		h.AddInfo(string(state)+"Count", stats.Count)
+		h.AddInfo(string(state)+"OldestAge", stats.OldestAge.String())
+		if stats.Count > c.maxCount || stats.OldestAge > c.maxAge {
+			h.Down()
+		}
+	}
+	return h
+}
+
+// atomicGroupChecker reports the number of atomic groups still pending in
+// the pool, purely informational since there's no inherent threshold at
+// which a count of in-flight atomic groups indicates degradation
+type atomicGroupChecker struct {
+	countActive func() (int, error)
+}
+
+// NewAtomicGroupChecker returns a health.Checker that reports the number of
+// active (not yet fully forged or invalidated) atomic groups in the pool.
+// countActive is called on every check; pass a closure over
+// l2db.CountActiveAtomicGroupsAPI.
+func NewAtomicGroupChecker(countActive func() (int, error)) health.Checker {
+	return &atomicGroupChecker{countActive: countActive}
+}
+
+// Check implements health.Checker
+func (c *atomicGroupChecker) Check() health.Health {
+	h := health.NewHealth()
+	count, err := c.countActive()
+	if err != nil {
+		h.Down().AddInfo("error", err.Error())
+		return h
+	}
+	h.Up().AddInfo("activeAtomicGroups", count)
+	return h
+}
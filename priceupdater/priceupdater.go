@@ -0,0 +1,346 @@
+/*
+Package priceupdater fetches the USD price of the registered tokens from one
+or more external price sources and persists the result into the historyDB so
+that it can be exposed through the API.
+*/
+package priceupdater
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hermeznetwork/hermez-node/db/historydb"
+	"github.com/hermeznetwork/hermez-node/log"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// Policy defines how the quotes returned by the enabled PriceProviders are
+// combined into a single price for a token.
+type Policy string
+
+const (
+	// PolicyFirstAvailable uses the first provider (following the
+	// configured order) that returns a fresh quote
+	PolicyFirstAvailable Policy = "FirstAvailable"
+	// PolicyMedian uses the median of all the fresh quotes
+	PolicyMedian Policy = "Median"
+	// PolicyWeightedMedian uses the median of all the fresh quotes,
+	// weighted by the per-provider Weight
+	PolicyWeightedMedian Policy = "WeightedMedian"
+)
+
+const (
+	// defaultProviderTimeout is the timeout used to query a single
+	// PriceProvider when none is specified in the Config
+	defaultProviderTimeout = 5 * time.Second
+	// defaultMaxStaleness is the maximum time a token can go without a
+	// fresh quote before its USD price is considered stale and reset to
+	// nil instead of serving an old value
+	defaultMaxStaleness = 10 * time.Minute
+	// defaultDeviationBand is the maximum allowed deviation (as a
+	// fraction of the running median) for a quote to be accepted
+	defaultDeviationBand = 0.1
+)
+
+// Quote is a single price observation for a token, as returned by a
+// PriceProvider
+type Quote struct {
+	Provider string
+	Symbol   string
+	USD      float64
+	// providerIndex is this Quote's provider's position in
+	// PriceUpdater.providers, i.e. the configured priority order.
+	// fetchAll's goroutines complete in non-deterministic order, so
+	// choosePrice sorts on this instead of relying on append order.
+	providerIndex int
+}
+
+// PriceProvider is implemented by every external price source that the
+// PriceUpdater can query
+type PriceProvider interface {
+	// Name identifies the provider, used for metrics, the per-token
+	// override map and error messages
+	Name() string
+	// FetchPrices returns the USD price for the given list of token
+	// symbols. Implementations are expected to only return entries for
+	// the symbols they were able to fetch
+	FetchPrices(ctx context.Context, symbols []string) (map[string]float64, error)
+}
+
+// providerState keeps the circuit-breaker state for a single PriceProvider
+type providerState struct {
+	provider        PriceProvider
+	weight          float64
+	consecutiveErrs int
+	openUntil       time.Time
+}
+
+// the circuit breaker opens after this many consecutive failures, and stays
+// open (the provider is skipped) for circuitBreakerCooldown
+const (
+	circuitBreakerThreshold = 3
+	circuitBreakerCooldown  = 1 * time.Minute
+)
+
+func (ps *providerState) isOpen(now time.Time) bool {
+	return now.Before(ps.openUntil)
+}
+
+func (ps *providerState) recordSuccess() {
+	ps.consecutiveErrs = 0
+	ps.openUntil = time.Time{}
+}
+
+func (ps *providerState) recordFailure(now time.Time) {
+	ps.consecutiveErrs++
+	if ps.consecutiveErrs >= circuitBreakerThreshold {
+		ps.openUntil = now.Add(circuitBreakerCooldown)
+	}
+}
+
+// Config configures a PriceUpdater
+type Config struct {
+	// Policy used to combine the quotes of the enabled providers
+	Policy Policy
+	// ProviderTimeout is the per-provider timeout applied when querying
+	// prices. 0 means defaultProviderTimeout
+	ProviderTimeout time.Duration
+	// MaxStaleness is the maximum time a token can go without a fresh
+	// quote before Token.USD is set back to nil. 0 means
+	// defaultMaxStaleness
+	MaxStaleness time.Duration
+	// DeviationBand is the maximum allowed deviation (as a fraction of
+	// the running median) for a quote to be accepted. 0 means
+	// defaultDeviationBand
+	DeviationBand float64
+	// TokenProviderOverride pins a TokenSymbol to a single provider
+	// (identified by PriceProvider.Name), ignoring the rest of the
+	// providers for that token
+	TokenProviderOverride map[string]string
+}
+
+// PriceUpdater is in charge of getting the USD price of the registered
+// tokens and persisting it into the historyDB
+type PriceUpdater struct {
+	providers []*providerState
+	cfg       Config
+	hdb       *historydb.HistoryDB
+
+	lastUpdate map[string]time.Time
+}
+
+// NewPriceUpdater creates a new PriceUpdater. providers is queried in the
+// given order; when cfg.Policy is PolicyFirstAvailable, that order is also
+// the priority used to pick the first fresh quote
+func NewPriceUpdater(cfg Config, providers []PriceProvider, hdb *historydb.HistoryDB) *PriceUpdater {
+	states := make([]*providerState, len(providers))
+	for i, p := range providers {
+		states[i] = &providerState{provider: p, weight: 1}
+	}
+	if cfg.Policy == "" {
+		cfg.Policy = PolicyFirstAvailable
+	}
+	if cfg.ProviderTimeout == 0 {
+		cfg.ProviderTimeout = defaultProviderTimeout
+	}
+	if cfg.MaxStaleness == 0 {
+		cfg.MaxStaleness = defaultMaxStaleness
+	}
+	if cfg.DeviationBand == 0 {
+		cfg.DeviationBand = defaultDeviationBand
+	}
+	return &PriceUpdater{
+		providers:  states,
+		cfg:        cfg,
+		hdb:        hdb,
+		lastUpdate: make(map[string]time.Time),
+	}
+}
+
+// SetProviderWeight sets the weight used by PolicyWeightedMedian for the
+// provider with the given name. Providers default to weight 1
+func (p *PriceUpdater) SetProviderWeight(name string, weight float64) {
+	for _, ps := range p.providers {
+		if ps.provider.Name() == name {
+			ps.weight = weight
+			return
+		}
+	}
+}
+
+// UpdatePrices queries every enabled provider in parallel for the USD price
+// of every token already registered in the historyDB, combines the quotes
+// following the configured Policy and persists the resulting price (plus the
+// raw quotes, for auditing) into the historyDB. Tokens that didn't get a
+// fresh quote within cfg.MaxStaleness have their USD price reset to nil
+// instead of keeping a stale value.
+func (p *PriceUpdater) UpdatePrices() {
+	tokens, err := p.hdb.GetAllTokens()
+	if err != nil {
+		log.Errorw("priceupdater: GetAllTokens", "err", err)
+		return
+	}
+	symbols := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if token.Symbol == "" {
+			continue
+		}
+		symbols = append(symbols, token.Symbol)
+	}
+
+	now := time.Now()
+	quotesBySymbol := p.fetchAll(symbols, now)
+
+	for _, token := range tokens {
+		quotes := quotesBySymbol[token.Symbol]
+		price, rawQuotes, ok := p.choosePrice(token.Symbol, quotes)
+		if !ok {
+			if last, seen := p.lastUpdate[token.Symbol]; !seen || now.Sub(last) > p.cfg.MaxStaleness {
+				if err := p.hdb.UpdateTokenValue(token.Symbol, nil); err != nil {
+					log.Errorw("priceupdater: UpdateTokenValue", "token", token.Symbol, "err", err)
+				}
+			}
+			continue
+		}
+		p.lastUpdate[token.Symbol] = now
+		if err := p.hdb.UpdateTokenValueWithQuotes(token.Symbol, price, rawQuotes); err != nil {
+			log.Errorw("priceupdater: UpdateTokenValueWithQuotes", "token", token.Symbol, "err", err)
+		}
+	}
+}
+
+// fetchAll queries every enabled (non-circuit-broken) provider in parallel,
+// respecting the per-provider timeout, and returns the resulting quotes
+// grouped by symbol
+func (p *PriceUpdater) fetchAll(symbols []string, now time.Time) map[string][]Quote {
+	var (
+		mu     sync.Mutex
+		result = make(map[string][]Quote)
+		wg     sync.WaitGroup
+	)
+	for i, ps := range p.providers {
+		if ps.isOpen(now) {
+			continue
+		}
+		i, ps := i, ps
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), p.cfg.ProviderTimeout)
+			defer cancel()
+			prices, err := ps.provider.FetchPrices(ctx, symbols)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Debugw("priceupdater: provider fetch failed",
+					"provider", ps.provider.Name(), "err", err)
+				ps.recordFailure(now)
+				return
+			}
+			ps.recordSuccess()
+			for symbol, usd := range prices {
+				result[symbol] = append(result[symbol], Quote{
+					Provider:      ps.provider.Name(),
+					Symbol:        symbol,
+					USD:           usd,
+					providerIndex: i,
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return result
+}
+
+// choosePrice combines the quotes for a single token following cfg.Policy,
+// honouring cfg.TokenProviderOverride and discarding quotes that deviate
+// from the running median by more than cfg.DeviationBand. It returns false
+// if no quote could be accepted.
+func (p *PriceUpdater) choosePrice(symbol string, quotes []Quote) (float64, []Quote, bool) {
+	if override, ok := p.cfg.TokenProviderOverride[symbol]; ok {
+		for _, q := range quotes {
+			if q.Provider == override {
+				return q.USD, []Quote{q}, true
+			}
+		}
+		return 0, nil, false
+	}
+	if len(quotes) == 0 {
+		return 0, nil, false
+	}
+
+	median := medianOf(quotes)
+	accepted := make([]Quote, 0, len(quotes))
+	for _, q := range quotes {
+		if math.Abs(q.USD-median)/median <= p.cfg.DeviationBand {
+			accepted = append(accepted, q)
+		}
+	}
+	if len(accepted) == 0 {
+		return 0, nil, false
+	}
+
+	switch p.cfg.Policy {
+	case PolicyFirstAvailable:
+		first := accepted[0]
+		for _, q := range accepted[1:] {
+			if q.providerIndex < first.providerIndex {
+				first = q
+			}
+		}
+		return first.USD, accepted, true
+	case PolicyMedian:
+		return medianOf(accepted), accepted, true
+	case PolicyWeightedMedian:
+		return p.weightedMedianOf(accepted), accepted, true
+	default:
+		log.Error(tracerr.Wrap(fmt.Errorf("priceupdater: unknown policy %q", p.cfg.Policy)))
+		return 0, nil, false
+	}
+}
+
+func medianOf(quotes []Quote) float64 {
+	values := make([]float64, len(quotes))
+	for i, q := range quotes {
+		values[i] = q.USD
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+func (p *PriceUpdater) weightedMedianOf(quotes []Quote) float64 {
+	type weighted struct {
+		usd    float64
+		weight float64
+	}
+	ws := make([]weighted, len(quotes))
+	totalWeight := 0.0
+	for i, q := range quotes {
+		w := 1.0
+		for _, ps := range p.providers {
+			if ps.provider.Name() == q.Provider {
+				w = ps.weight
+				break
+			}
+		}
+		ws[i] = weighted{usd: q.USD, weight: w}
+		totalWeight += w
+	}
+	sort.Slice(ws, func(i, j int) bool { return ws[i].usd < ws[j].usd })
+	acc := 0.0
+	for _, w := range ws {
+		acc += w.weight
+		if acc >= totalWeight/2 {
+			return w.usd
+		}
+	}
+	return ws[len(ws)-1].usd
+}
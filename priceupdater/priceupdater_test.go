@@ -36,10 +36,10 @@ func TestPriceUpdater(t *testing.T) {
 		Decimals:    18,
 	})
 	assert.NoError(t, historyDB.AddTokens(tokens))
-	// Init price updater
-	pu := NewPriceUpdater("https://api-pub.bitfinex.com/v2/", historyDB)
-	// Update token list
-	assert.NoError(t, pu.UpdateTokenList())
+	// Init price updater, using Bitfinex as the only (and therefore
+	// authoritative) source
+	bitfinex := NewBitfinexProvider("https://api-pub.bitfinex.com/v2/")
+	pu := NewPriceUpdater(Config{Policy: PolicyFirstAvailable}, []PriceProvider{bitfinex}, historyDB)
 	// Update prices
 	pu.UpdatePrices()
 	// Check that prices have been updated
@@ -0,0 +1,236 @@
+package priceupdater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// httpClient is the client used by the HTTP-based providers. A dedicated
+// client (instead of http.DefaultClient) lets tests swap in a mock
+// transport
+var httpClient = &http.Client{Timeout: defaultProviderTimeout}
+
+// BitfinexProvider fetches prices from the Bitfinex REST API
+type BitfinexProvider struct {
+	apiURL string
+}
+
+// NewBitfinexProvider returns a BitfinexProvider that queries apiURL (e.g.
+// "https://api-pub.bitfinex.com/v2/")
+func NewBitfinexProvider(apiURL string) *BitfinexProvider {
+	return &BitfinexProvider{apiURL: apiURL}
+}
+
+// Name implements PriceProvider
+func (b *BitfinexProvider) Name() string { return "bitfinex" }
+
+// FetchPrices implements PriceProvider
+func (b *BitfinexProvider) FetchPrices(ctx context.Context, symbols []string) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	for _, symbol := range symbols {
+		url := fmt.Sprintf("%sticker/t%sUSD", b.apiURL, symbol)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		res, err := httpClient.Do(req)
+		if err != nil {
+			// a single unreachable symbol shouldn't fail the whole batch
+			continue
+		}
+		var ticker []float64
+		err = json.NewDecoder(res.Body).Decode(&ticker)
+		res.Body.Close() //nolint:errcheck
+		if err != nil || len(ticker) < 7 {
+			continue
+		}
+		const lastPriceIdx = 6
+		prices[symbol] = ticker[lastPriceIdx]
+	}
+	return prices, nil
+}
+
+// CoinGeckoProvider fetches prices from the CoinGecko REST API
+type CoinGeckoProvider struct {
+	apiURL string
+	// ids maps a token symbol to its CoinGecko coin id (e.g. "ETH" -> "ethereum")
+	ids map[string]string
+}
+
+// NewCoinGeckoProvider returns a CoinGeckoProvider that queries apiURL
+// (e.g. "https://api.coingecko.com/api/v3/"), translating symbols to
+// CoinGecko coin ids using ids
+func NewCoinGeckoProvider(apiURL string, ids map[string]string) *CoinGeckoProvider {
+	return &CoinGeckoProvider{apiURL: apiURL, ids: ids}
+}
+
+// Name implements PriceProvider
+func (c *CoinGeckoProvider) Name() string { return "coingecko" }
+
+// FetchPrices implements PriceProvider
+func (c *CoinGeckoProvider) FetchPrices(ctx context.Context, symbols []string) (map[string]float64, error) {
+	coinIDs := make([]string, 0, len(symbols))
+	symbolByID := make(map[string]string)
+	for _, symbol := range symbols {
+		id, ok := c.ids[symbol]
+		if !ok {
+			continue
+		}
+		coinIDs = append(coinIDs, id)
+		symbolByID[id] = symbol
+	}
+	if len(coinIDs) == 0 {
+		return nil, nil
+	}
+	url := fmt.Sprintf("%ssimple/price?ids=%s&vs_currencies=usd", c.apiURL, joinComma(coinIDs))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+	var body map[string]struct {
+		USD float64 `json:"usd"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	prices := make(map[string]float64)
+	for id, entry := range body {
+		prices[symbolByID[id]] = entry.USD
+	}
+	return prices, nil
+}
+
+func joinComma(ss []string) string {
+	out := ""
+	for i, s := range ss {
+		if i > 0 {
+			out += ","
+		}
+		out += s
+	}
+	return out
+}
+
+// KrakenProvider fetches prices from the Kraken REST API
+type KrakenProvider struct {
+	apiURL string
+}
+
+// NewKrakenProvider returns a KrakenProvider that queries apiURL (e.g.
+// "https://api.kraken.com/0/public/")
+func NewKrakenProvider(apiURL string) *KrakenProvider {
+	return &KrakenProvider{apiURL: apiURL}
+}
+
+// Name implements PriceProvider
+func (k *KrakenProvider) Name() string { return "kraken" }
+
+// FetchPrices implements PriceProvider
+func (k *KrakenProvider) FetchPrices(ctx context.Context, symbols []string) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	for _, symbol := range symbols {
+		pair := symbol + "USD"
+		url := fmt.Sprintf("%sTicker?pair=%s", k.apiURL, pair)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		res, err := httpClient.Do(req)
+		if err != nil {
+			continue
+		}
+		var body struct {
+			Result map[string]struct {
+				C []string `json:"c"`
+			} `json:"result"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&body)
+		res.Body.Close() //nolint:errcheck
+		if err != nil {
+			continue
+		}
+		for _, ticker := range body.Result {
+			if len(ticker.C) == 0 {
+				continue
+			}
+			var usd float64
+			if _, err := fmt.Sscanf(ticker.C[0], "%f", &usd); err == nil {
+				prices[symbol] = usd
+			}
+			break
+		}
+	}
+	return prices, nil
+}
+
+// ChainlinkReader is the subset of an ethereum client needed to read a
+// Chainlink aggregator's latest answer
+type ChainlinkReader interface {
+	CallContract(ctx context.Context, callData []byte, aggregator ethCommon.Address) ([]byte, error)
+}
+
+// chainlinkLatestAnswerSelector is the 4-byte selector of
+// AggregatorV3Interface.latestRoundData()
+var chainlinkLatestAnswerSelector = []byte{0xfe, 0xaf, 0x96, 0x8c}
+
+// ChainlinkProvider reads USD prices from on-chain Chainlink aggregators
+type ChainlinkProvider struct {
+	client ChainlinkReader
+	// aggregators maps a token symbol to the address of its Chainlink
+	// USD price aggregator
+	aggregators map[string]ethCommon.Address
+	// decimals is the number of decimals used by the aggregators
+	// (Chainlink USD feeds use 8 by convention)
+	decimals uint8
+}
+
+// NewChainlinkProvider returns a ChainlinkProvider that reads the given
+// aggregators through client
+func NewChainlinkProvider(client ChainlinkReader, aggregators map[string]ethCommon.Address,
+	decimals uint8) *ChainlinkProvider {
+	return &ChainlinkProvider{client: client, aggregators: aggregators, decimals: decimals}
+}
+
+// Name implements PriceProvider
+func (c *ChainlinkProvider) Name() string { return "chainlink" }
+
+// FetchPrices implements PriceProvider
+func (c *ChainlinkProvider) FetchPrices(ctx context.Context, symbols []string) (map[string]float64, error) {
+	prices := make(map[string]float64)
+	for _, symbol := range symbols {
+		aggregator, ok := c.aggregators[symbol]
+		if !ok {
+			continue
+		}
+		out, err := c.client.CallContract(ctx, chainlinkLatestAnswerSelector, aggregator)
+		if err != nil {
+			continue
+		}
+		// latestRoundData returns (roundId, answer, startedAt,
+		// updatedAt, answeredInRound); answer is the 2nd word
+		const wordSize = 32
+		if len(out) < 2*wordSize {
+			continue
+		}
+		answer := new(big.Int).SetBytes(out[wordSize : 2*wordSize])
+		divisor := new(big.Float).SetFloat64(1)
+		for i := uint8(0); i < c.decimals; i++ {
+			divisor.Mul(divisor, big.NewFloat(10)) //nolint:gomnd
+		}
+		usd := new(big.Float).Quo(new(big.Float).SetInt(answer), divisor)
+		f, _ := usd.Float64()
+		prices[symbol] = f
+	}
+	return prices, nil
+}
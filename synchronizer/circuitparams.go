@@ -0,0 +1,50 @@
+package synchronizer
+
+import (
+	"fmt"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/txprocessor"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// circuitParamsL1Fraction is the node-side policy used to derive a
+// verifier's MaxL1Tx/MaxFeeTx from its on-chain MaxTx: the Rollup contract
+// only commits RollupVerifierStruct.MaxTx/NLevels on chain, and 1/8th
+// reproduces the values the previous hardcoded
+// txprocessor.Config{MaxTx: 512, MaxL1Tx: 64, MaxFeeTx: 64} used.
+const circuitParamsL1Fraction = 8 //nolint:gomnd
+
+// circuitParamsByVerifier builds a verifierIdx -> txprocessor.Config
+// registry from consts.Verifiers (populated by the Rollup contract's
+// AddVerifier events via RollupConstants), so rollupSync can replay each
+// batch with the MaxTx/NLevels dimensions the verifier it was actually
+// proven with supports, instead of a single hardcoded guess.
+func circuitParamsByVerifier(consts *common.RollupConstants) map[int]txprocessor.Config {
+	params := make(map[int]txprocessor.Config, len(consts.Verifiers))
+	for i, v := range consts.Verifiers {
+		maxTx := uint32(v.MaxTx)
+		params[i] = txprocessor.Config{
+			NLevels:  uint32(v.NLevels),
+			MaxTx:    maxTx,
+			MaxL1Tx:  maxTx / circuitParamsL1Fraction,
+			MaxFeeTx: maxTx / circuitParamsL1Fraction,
+			ChainID:  uint16(consts.ChainID),
+		}
+	}
+	return params
+}
+
+// circuitParamsFor returns the txprocessor.Config registered for
+// verifierIdx, failing loudly instead of silently replaying a batch with
+// the wrong circuit dimensions when no AddVerifier event ever registered
+// that index.
+func (s *Synchronizer) circuitParamsFor(verifierIdx uint8) (*txprocessor.Config, error) {
+	cfg, ok := s.circuitParams[int(verifierIdx)]
+	if !ok {
+		return nil, tracerr.Wrap(fmt.Errorf("rollupSync: batch forged with unknown "+
+			"verifierIdx %v: no AddVerifier event ever registered its circuit "+
+			"parameters", verifierIdx))
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,66 @@
+package synchronizer
+
+import (
+	"strings"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-node/log"
+)
+
+// fallbackERC20Decimals is the decimals() value assumed when a token
+// implements neither the string nor the bytes32 ABI for it: the ERC-20
+// spec recommends tokens default to 18 when decimals() is omitted.
+const fallbackERC20Decimals = 18 //nolint:gomnd
+
+// Bytes32ERC20Consts mirrors EthERC20Consts for ERC-20 tokens that predate
+// the EIP-20 string ABI (MKR, SAI, and other early tokens return bytes32
+// for name()/symbol()). Decimals is nil when the token doesn't implement
+// decimals() at all.
+type Bytes32ERC20Consts struct {
+	Name     [32]byte
+	Symbol   [32]byte
+	Decimals *uint64
+}
+
+// ERC20Bytes32Reader is an optional capability an eth.ClientInterface
+// implementation can satisfy to decode ERC-20 tokens whose name()/symbol()
+// return bytes32 instead of string. Synchronizer detects it via a type
+// assertion on ethClient, the same way BlockSubscriber is detected.
+type ERC20Bytes32Reader interface {
+	EthERC20ConstsBytes32(addr ethCommon.Address) (*Bytes32ERC20Consts, error)
+}
+
+// resolveERC20Consts recovers a usable name/symbol/decimals for addr when
+// the standard string-returning EthERC20Consts call (whose error is
+// firstErr) failed, by retrying through ERC20Bytes32Reader and trimming
+// the trailing zero bytes of the bytes32 result to a UTF-8 string. Only
+// when that also fails (or ethClient can't attempt it) does it fall back
+// to the "ERC20_ETH_ERROR"/"ERROR" placeholder, logging a structured
+// warning in that case so operators can decide whether to blacklist the
+// token instead of silently corrupting its metadata.
+func (s *Synchronizer) resolveERC20Consts(addr ethCommon.Address,
+	firstErr error) (name, symbol string, decimals uint64) {
+	reader, ok := s.ethClient.(ERC20Bytes32Reader)
+	if !ok {
+		log.Warnw("ERC20 token metadata unavailable: string ABI failed and ethClient "+
+			"can't attempt the bytes32 fallback", "addr", addr, "err", firstErr)
+		return "ERC20_ETH_ERROR", "ERROR", 1
+	}
+	consts, err := reader.EthERC20ConstsBytes32(addr)
+	if err != nil {
+		log.Warnw("ERC20 token metadata unavailable via string or bytes32 ABI",
+			"addr", addr, "stringErr", firstErr, "bytes32Err", err)
+		return "ERC20_ETH_ERROR", "ERROR", 1
+	}
+	decimals = fallbackERC20Decimals
+	if consts.Decimals != nil {
+		decimals = *consts.Decimals
+	}
+	return bytes32ToString(consts.Name), bytes32ToString(consts.Symbol), decimals
+}
+
+// bytes32ToString trims b's trailing zero bytes and decodes the remainder
+// as UTF-8, the encoding pre-EIP-20 tokens use for name()/symbol()
+func bytes32ToString(b [32]byte) string {
+	return strings.TrimRight(string(b[:]), "\x00")
+}
@@ -0,0 +1,47 @@
+package synchronizer
+
+import "github.com/hermeznetwork/hermez-node/common"
+
+// SyncEventBus receives raw smart-contract events as rollupSync/
+// auctionSync/wdelayerSync discover them inside a single block, before
+// they're grouped into BlockData and committed to historyDB/stateDB.
+//
+// Unlike SyncEventHandler (post-commit, one notification per block/batch),
+// SyncEventBus fires inline at discovery time, while finalizeBlock extracts
+// a block's events and before they are committed, so coordinator/pool code
+// can react without polling HistoryDB. Like SyncEventHandler, it only fires
+// for blocks that are already FinalityDepth deep. It's best-effort and not
+// reorg-aware: subscribers are never told to retract an event; reorgs are
+// reported only through SyncEventHandler.OnReorg.
+type SyncEventBus interface {
+	BatchForged(batch *common.Batch)
+	L1UserTxQueued(tx *common.L1Tx)
+	WithdrawalInitiated(withdrawal *common.WithdrawInfo)
+	EmergencyModeEnabled()
+	NewBid(bid *common.Bid)
+	AddToken(token *common.Token)
+	UpdateBucketsParameters(update *common.BucketUpdate)
+}
+
+// SetEventBus registers the SyncEventBus that rollupSync/auctionSync/
+// wdelayerSync notify as they discover events. Pass nil (the default) to
+// disable event-bus notifications entirely.
+func (s *Synchronizer) SetEventBus(bus SyncEventBus) {
+	if bus == nil {
+		bus = noopEventBus{}
+	}
+	s.eventBus = bus
+}
+
+// noopEventBus is the zero-value SyncEventBus: every method is a no-op, so
+// rollupSync/auctionSync/wdelayerSync can call s.eventBus unconditionally
+// without a nil check at every call site
+type noopEventBus struct{}
+
+func (noopEventBus) BatchForged(*common.Batch)                    {}
+func (noopEventBus) L1UserTxQueued(*common.L1Tx)                  {}
+func (noopEventBus) WithdrawalInitiated(*common.WithdrawInfo)     {}
+func (noopEventBus) EmergencyModeEnabled()                        {}
+func (noopEventBus) NewBid(*common.Bid)                           {}
+func (noopEventBus) AddToken(*common.Token)                       {}
+func (noopEventBus) UpdateBucketsParameters(*common.BucketUpdate) {}
@@ -0,0 +1,137 @@
+package synchronizer
+
+import (
+	"sync"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/log"
+)
+
+// busEventKind identifies which SyncEventBus method a busEvent should be
+// dispatched through
+type busEventKind int
+
+const (
+	eventBatchForged busEventKind = iota
+	eventL1UserTxQueued
+	eventWithdrawalInitiated
+	eventEmergencyModeEnabled
+	eventNewBid
+	eventAddToken
+	eventUpdateBucketsParameters
+)
+
+// busEvent is the single concrete type FanOutEventBus buffers and fans
+// out, carrying whichever payload kind indicates
+type busEvent struct {
+	kind       busEventKind
+	batch      *common.Batch
+	l1Tx       *common.L1Tx
+	withdrawal *common.WithdrawInfo
+	bid        *common.Bid
+	token      *common.Token
+	bucket     *common.BucketUpdate
+}
+
+// FanOutEventBus is a concrete, in-process SyncEventBus: every method
+// pushes its event onto a single buffered channel, and a background
+// goroutine drains it, dispatching each event to every subscriber
+// registered via Subscribe. A full buffer drops the new event rather than
+// blocking the synchronizer, and logs a warning when that happens.
+type FanOutEventBus struct {
+	events chan busEvent
+
+	mu   sync.RWMutex
+	subs []SyncEventBus
+}
+
+// NewFanOutEventBus creates a FanOutEventBus with the given channel buffer
+// size and starts its dispatch goroutine
+func NewFanOutEventBus(bufferSize int) *FanOutEventBus {
+	b := &FanOutEventBus{
+		events: make(chan busEvent, bufferSize),
+	}
+	go b.run()
+	return b
+}
+
+// Subscribe registers sub to receive every event FanOutEventBus dispatches
+// from now on
+func (b *FanOutEventBus) Subscribe(sub SyncEventBus) {
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+}
+
+func (b *FanOutEventBus) run() {
+	for evt := range b.events {
+		b.mu.RLock()
+		subs := b.subs
+		b.mu.RUnlock()
+		for _, sub := range subs {
+			dispatch(sub, evt)
+		}
+	}
+}
+
+func dispatch(sub SyncEventBus, evt busEvent) {
+	switch evt.kind {
+	case eventBatchForged:
+		sub.BatchForged(evt.batch)
+	case eventL1UserTxQueued:
+		sub.L1UserTxQueued(evt.l1Tx)
+	case eventWithdrawalInitiated:
+		sub.WithdrawalInitiated(evt.withdrawal)
+	case eventEmergencyModeEnabled:
+		sub.EmergencyModeEnabled()
+	case eventNewBid:
+		sub.NewBid(evt.bid)
+	case eventAddToken:
+		sub.AddToken(evt.token)
+	case eventUpdateBucketsParameters:
+		sub.UpdateBucketsParameters(evt.bucket)
+	}
+}
+
+func (b *FanOutEventBus) push(evt busEvent) {
+	select {
+	case b.events <- evt:
+	default:
+		log.Warnw("FanOutEventBus: buffer full, dropping event", "kind", evt.kind)
+	}
+}
+
+// BatchForged implements SyncEventBus
+func (b *FanOutEventBus) BatchForged(batch *common.Batch) {
+	b.push(busEvent{kind: eventBatchForged, batch: batch})
+}
+
+// L1UserTxQueued implements SyncEventBus
+func (b *FanOutEventBus) L1UserTxQueued(tx *common.L1Tx) {
+	b.push(busEvent{kind: eventL1UserTxQueued, l1Tx: tx})
+}
+
+// WithdrawalInitiated implements SyncEventBus
+func (b *FanOutEventBus) WithdrawalInitiated(withdrawal *common.WithdrawInfo) {
+	b.push(busEvent{kind: eventWithdrawalInitiated, withdrawal: withdrawal})
+}
+
+// EmergencyModeEnabled implements SyncEventBus
+func (b *FanOutEventBus) EmergencyModeEnabled() {
+	b.push(busEvent{kind: eventEmergencyModeEnabled})
+}
+
+// NewBid implements SyncEventBus
+func (b *FanOutEventBus) NewBid(bid *common.Bid) {
+	b.push(busEvent{kind: eventNewBid, bid: bid})
+}
+
+// AddToken implements SyncEventBus
+func (b *FanOutEventBus) AddToken(token *common.Token) {
+	b.push(busEvent{kind: eventAddToken, token: token})
+}
+
+// UpdateBucketsParameters implements SyncEventBus
+func (b *FanOutEventBus) UpdateBucketsParameters(update *common.BucketUpdate) {
+	b.push(busEvent{kind: eventUpdateBucketsParameters, bucket: update})
+}
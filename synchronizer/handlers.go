@@ -0,0 +1,53 @@
+package synchronizer
+
+import "github.com/hermeznetwork/hermez-node/common"
+
+// SyncEventHandler receives notifications for the events Sync2/reorg/
+// updateCurrentSlotIfSync produce, after they've already been durably
+// applied to historyDB/stateDB (or, for OnReorg, after historyDB/stateDB
+// have been rolled back to the valid block). Implementations must not
+// block: every registered handler is invoked synchronously, in
+// registration order, so a slow handler stalls synchronization.
+type SyncEventHandler interface {
+	// OnBlock is called once a block and every batch/event in it have
+	// been stored
+	OnBlock(blockData *common.BlockData)
+	// OnBatch is called once per batch within OnBlock's block, in forge
+	// order, right before OnBlock fires for that block
+	OnBatch(batchData *common.BatchData)
+	// OnReorg is called after a reorg has been resolved; from is the
+	// previously synced tip, to is the block number synchronization
+	// resumed from
+	OnReorg(from, to int64)
+	// OnSlotChange is called whenever updateCurrentSlotIfSync observes
+	// the auction slot number roll over to a new slot
+	OnSlotChange(slot common.Slot)
+}
+
+// RegisterHandler adds h to the set of SyncEventHandlers notified by
+// Sync2, reorg and updateCurrentSlotIfSync. Not safe to call concurrently
+// with a Sync2 call in progress.
+func (s *Synchronizer) RegisterHandler(h SyncEventHandler) {
+	s.handlers = append(s.handlers, h)
+}
+
+func (s *Synchronizer) notifyBlock(blockData *common.BlockData) {
+	for _, h := range s.handlers {
+		for i := range blockData.Rollup.Batches {
+			h.OnBatch(&blockData.Rollup.Batches[i])
+		}
+		h.OnBlock(blockData)
+	}
+}
+
+func (s *Synchronizer) notifyReorg(from, to int64) {
+	for _, h := range s.handlers {
+		h.OnReorg(from, to)
+	}
+}
+
+func (s *Synchronizer) notifySlotChange(slot common.Slot) {
+	for _, h := range s.handlers {
+		h.OnSlotChange(slot)
+	}
+}
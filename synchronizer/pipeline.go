@@ -0,0 +1,146 @@
+package synchronizer
+
+import (
+	"sync"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/eth"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// PipelineMetrics accumulates the wall-clock time rollupSync has spent in
+// fetchForgeBatchArgs (the RollupForgeBatchArgs RPCs) versus the rest of
+// rollupSync after that fetch (replaying batches into stateDB, which also
+// includes its EthERC20Consts calls), so operators can tell whether a slow
+// sync is network-bound or CPU-bound. Event fetches are not timed.
+type PipelineMetrics struct {
+	mu      sync.Mutex
+	rpcWait time.Duration
+	replay  time.Duration
+}
+
+func (m *PipelineMetrics) addRPCWait(d time.Duration) {
+	m.mu.Lock()
+	m.rpcWait += d
+	m.mu.Unlock()
+}
+
+func (m *PipelineMetrics) addReplay(d time.Duration) {
+	m.mu.Lock()
+	m.replay += d
+	m.mu.Unlock()
+}
+
+// Snapshot returns the accumulated durations so far
+func (m *PipelineMetrics) Snapshot() (rpcWait, replay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rpcWait, m.replay
+}
+
+// syncContracts runs rollupSync, auctionSync and wdelayerSync for ethBlock
+// concurrently: the three contracts are independent RPC sources and
+// nothing in finalizeBlock needs one's result before starting the others
+// (their outputs are only joined together afterwards). The first error
+// from any of the three is returned; the other two are still awaited
+// before returning, since they were already started.
+func (s *Synchronizer) syncContracts(ethBlock *common.Block) (*common.RollupData,
+	*common.AuctionData, *common.WDelayerData, error) {
+	var (
+		rollupData   *common.RollupData
+		auctionData  *common.AuctionData
+		wDelayerData *common.WDelayerData
+
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		data, err := s.rollupSync(ethBlock)
+		if err != nil {
+			setErr(tracerr.Wrap(err))
+			return
+		}
+		rollupData = data
+	}()
+	go func() {
+		defer wg.Done()
+		data, err := s.auctionSync(ethBlock)
+		if err != nil {
+			setErr(tracerr.Wrap(err))
+			return
+		}
+		auctionData = data
+	}()
+	go func() {
+		defer wg.Done()
+		data, err := s.wdelayerSync(ethBlock)
+		if err != nil {
+			setErr(tracerr.Wrap(err))
+			return
+		}
+		wDelayerData = data
+	}()
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, nil, nil, firstErr
+	}
+	return rollupData, auctionData, wDelayerData, nil
+}
+
+// forgeBatchArgsResult is the outcome of one RollupForgeBatchArgs RPC,
+// kept alongside its originating event so fetchForgeBatchArgs can return
+// results in the same order the events were given in.
+type forgeBatchArgsResult struct {
+	args   *eth.RollupForgeBatchArgs
+	sender *ethCommon.Address
+	err    error
+}
+
+// fetchForgeBatchArgs fetches RollupForgeBatchArgs for every event in
+// events concurrently, bounded to workers in flight at once (workers <= 1
+// runs them sequentially), and returns the results in the same order as
+// events. Extraction (this RPC) is the only part of rollupSync that's
+// parallelized: the caller must still replay the returned batches in
+// order, since stateDB mutation is not safe to do out of order.
+func (s *Synchronizer) fetchForgeBatchArgs(events []eth.RollupEventForgeBatch,
+	workers int) ([]forgeBatchArgsResult, error) {
+	results := make([]forgeBatchArgsResult, len(events))
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, evt := range events {
+		i, evt := i, evt
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			args, sender, err := s.ethClient.RollupForgeBatchArgs(evt.EthTxHash,
+				evt.L1UserTxsLen)
+			results[i] = forgeBatchArgsResult{args: args, sender: sender, err: err}
+		}()
+	}
+	wg.Wait()
+	for _, r := range results {
+		if r.err != nil {
+			return nil, tracerr.Wrap(r.err)
+		}
+	}
+	return results, nil
+}
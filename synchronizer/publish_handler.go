@@ -0,0 +1,78 @@
+package synchronizer
+
+import (
+	"encoding/json"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/log"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// Publisher abstracts the pub/sub backend PublishHandler sends JSON
+// messages to, so the synchronizer doesn't depend directly on a NATS or
+// Kafka client: pass a thin adapter (e.g. *nats.Conn.Publish, or a Kafka
+// producer's SendMessage) wrapped to match this signature.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// PublishHandler is a built-in SyncEventHandler that serializes
+// common.BlockData (and reorg/slot-change notifications) to JSON and
+// publishes them via Publisher, so coordinators, explorers or bridges can
+// react to new batches without polling HistoryDB.
+type PublishHandler struct {
+	pub          Publisher
+	blockSubject string
+	reorgSubject string
+	slotSubject  string
+}
+
+// publishedReorg is the JSON payload PublishHandler sends on OnReorg
+type publishedReorg struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// NewPublishHandler creates a PublishHandler that publishes block updates
+// to subjectPrefix+".block", reorgs to subjectPrefix+".reorg" and slot
+// changes to subjectPrefix+".slot"
+func NewPublishHandler(pub Publisher, subjectPrefix string) *PublishHandler {
+	return &PublishHandler{
+		pub:          pub,
+		blockSubject: subjectPrefix + ".block",
+		reorgSubject: subjectPrefix + ".reorg",
+		slotSubject:  subjectPrefix + ".slot",
+	}
+}
+
+// OnBlock implements SyncEventHandler
+func (h *PublishHandler) OnBlock(blockData *common.BlockData) {
+	h.publish(h.blockSubject, blockData)
+}
+
+// OnBatch implements SyncEventHandler. PublishHandler doesn't publish a
+// separate message per batch: batches are already included in the
+// BlockData published by OnBlock.
+func (h *PublishHandler) OnBatch(batchData *common.BatchData) {}
+
+// OnReorg implements SyncEventHandler
+func (h *PublishHandler) OnReorg(from, to int64) {
+	h.publish(h.reorgSubject, &publishedReorg{From: from, To: to})
+}
+
+// OnSlotChange implements SyncEventHandler
+func (h *PublishHandler) OnSlotChange(slot common.Slot) {
+	h.publish(h.slotSubject, &slot)
+}
+
+func (h *PublishHandler) publish(subject string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Errorw("PublishHandler: failed to marshal message", "subject", subject, "err", err)
+		return
+	}
+	if err := h.pub.Publish(subject, data); err != nil {
+		log.Errorw("PublishHandler: failed to publish message",
+			"subject", subject, "err", tracerr.Wrap(err))
+	}
+}
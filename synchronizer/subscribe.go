@@ -0,0 +1,149 @@
+package synchronizer
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/log"
+)
+
+// BlockSubscriber is an optional capability an eth.ClientInterface
+// implementation can satisfy when its underlying transport supports
+// eth_subscribe (a websocket or IPC endpoint, as opposed to plain HTTP).
+// SyncLoop detects it via a type assertion on the ethClient passed to
+// NewSynchronizer, and uses it to be notified of new blocks as they
+// arrive instead of polling EthBlockByNumber on a fixed interval.
+type BlockSubscriber interface {
+	// SubscribeNewBlock subscribes to eth_subscribe("newHeads"),
+	// delivering each new block header (as a common.Block, consistent
+	// with the rest of eth.ClientInterface) on blocks until ctx is
+	// cancelled or the returned ethereum.Subscription is unsubscribed.
+	SubscribeNewBlock(ctx context.Context, blocks chan<- *common.Block) (ethereum.Subscription, error)
+}
+
+// SyncLoop repeatedly calls Sync2, advancing its own view of the last
+// synced block on every call, until ctx is done.
+//
+// When ethClient satisfies BlockSubscriber, SyncLoop subscribes to new
+// block headers and calls Sync2 as soon as one arrives, so
+// Stats.Eth.LastBlock and Stats.Sync.LastBlock can converge with
+// sub-second latency instead of waiting for a full PollingInterval. If
+// the subscription can't be established, or drops later on, SyncLoop
+// falls back to polling Sync2 every cfg.PollingInterval for the rest of
+// ctx's lifetime, exactly as it did before subscriptions existed.
+//
+// Because each Sync2 call only advances one block, and SyncLoop calls it
+// again immediately (without waiting for the next trigger) whenever it
+// returns a synced block or a reorg, backfill catch-up after startup or
+// after a gap happens for free: SyncLoop keeps calling Sync2 until it
+// reports the sync has caught up to head (a nil BlockData and nil
+// discarded count), and only then waits for the next new-block
+// notification or poll tick.
+//
+// onBlock, when non-nil, is called after every successful Sync2 call
+// that returned a block (discarded is non-nil instead on a detected
+// reorg). onError, when non-nil, is called after a failed Sync2 call;
+// SyncLoop never returns early because of it, it just waits for the next
+// trigger and retries.
+func (s *Synchronizer) SyncLoop(ctx context.Context, onBlock func(*common.BlockData, *int64),
+	onError func(error)) {
+	trigger := s.newBlockTrigger(ctx)
+	var lastSavedBlock *common.Block
+	for {
+		blockData, discarded, err := s.Sync2(ctx, lastSavedBlock)
+		switch {
+		case err != nil:
+			if onError != nil {
+				onError(err)
+			}
+		case discarded != nil:
+			// reorg: re-derive lastSavedBlock from the DB on the
+			// next Sync2 call, and keep backfilling without
+			// waiting for a trigger
+			lastSavedBlock = nil
+			continue
+		case blockData != nil:
+			lastSavedBlock = &blockData.Block
+			if onBlock != nil {
+				onBlock(blockData, discarded)
+			}
+			// more blocks may already be waiting: keep draining
+			// before going back to sleep
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-trigger:
+		}
+	}
+}
+
+// newBlockTrigger returns a channel that fires whenever SyncLoop should
+// attempt another Sync2 call: on every new block header if ethClient
+// satisfies BlockSubscriber, or every cfg.PollingInterval otherwise
+func (s *Synchronizer) newBlockTrigger(ctx context.Context) <-chan struct{} {
+	trigger := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case trigger <- struct{}{}:
+		default:
+		}
+	}
+	if subscriber, ok := s.ethClient.(BlockSubscriber); ok {
+		go s.subscribeTrigger(ctx, subscriber, notify)
+	} else {
+		go s.pollTrigger(ctx, notify)
+	}
+	return trigger
+}
+
+// pollTrigger notifies immediately and then every cfg.PollingInterval,
+// until ctx is done
+func (s *Synchronizer) pollTrigger(ctx context.Context, notify func()) {
+	notify()
+	ticker := time.NewTicker(s.cfg.PollingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			notify()
+		}
+	}
+}
+
+// subscribeTrigger subscribes to new block headers via subscriber and
+// notifies on every one of them. If the subscription can't be
+// established, or its error channel fires later on (the transport
+// dropped it), it falls back to pollTrigger for the remainder of ctx's
+// lifetime.
+func (s *Synchronizer) subscribeTrigger(ctx context.Context, subscriber BlockSubscriber,
+	notify func()) {
+	heads := make(chan *common.Block)
+	sub, err := subscriber.SubscribeNewBlock(ctx, heads)
+	if err != nil {
+		log.Warnw("SyncLoop: SubscribeNewBlock failed, falling back to polling", "err", err)
+		s.pollTrigger(ctx, notify)
+		return
+	}
+	defer sub.Unsubscribe()
+	// kick off an initial backfill without waiting for the first head
+	notify()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heads:
+			notify()
+		case err := <-sub.Err():
+			log.Warnw("SyncLoop: block subscription dropped, falling back to polling",
+				"err", err)
+			s.pollTrigger(ctx, notify)
+			return
+		}
+	}
+}
@@ -180,6 +180,21 @@ type SCConsts struct {
 // Config is the Synchronizer configuration
 type Config struct {
 	StatsRefreshPeriod time.Duration
+	// PollingInterval is how often SyncLoop calls Sync2 when ethClient
+	// doesn't satisfy BlockSubscriber, or its subscription has dropped
+	PollingInterval time.Duration
+	// FinalityDepth is how many blocks behind the ethereum head a block
+	// must be before Sync2 processes it into historyDB/stateDB. Blocks
+	// less deep than this are only held in Synchronizer's in-memory
+	// pending ring, so a reorg shallower than FinalityDepth never
+	// touches either DB. The zero value finalizes every block as soon
+	// as it's fetched, reproducing the pre-FinalityDepth behavior.
+	FinalityDepth int64
+	// ForgeBatchArgsWorkers bounds how many RollupForgeBatchArgs RPCs
+	// rollupSync issues concurrently while extracting a block's
+	// batches, before replaying them into stateDB in order. Values <= 1
+	// fetch them sequentially, reproducing the pre-pipelining behavior.
+	ForgeBatchArgsWorkers int
 }
 
 // Synchronizer implements the Synchronizer type
@@ -193,11 +208,42 @@ type Synchronizer struct {
 	startBlockNum int64
 	vars          SCVariables
 	stats         *StatsHolder
+	// snapshotPath, when non-empty, is read once by init() to hydrate
+	// historyDB/stateDB and skip ahead to the snapshot's block, instead
+	// of syncing from startBlockNum. See ImportSnapshot.
+	snapshotPath string
+	// pending holds the headers of blocks that have been fetched and
+	// chain-linked to their predecessor, but aren't yet FinalityDepth
+	// deep: Sync2 defers processing them into historyDB/stateDB until
+	// they are. pending[0] is the oldest (shallowest) pending block.
+	pending []*common.Block
+	// handlers are notified of sync events by Sync2/reorg/
+	// updateCurrentSlotIfSync; see RegisterHandler
+	handlers []SyncEventHandler
+	// eventBus is notified inline, at discovery time, by rollupSync/
+	// auctionSync/wdelayerSync; see SetEventBus
+	eventBus SyncEventBus
+	// circuitParams is the verifierIdx -> txprocessor.Config registry
+	// rollupSync looks up forgeBatchArgs.VerifierIdx against; see
+	// circuitParamsByVerifier.
+	circuitParams map[int]txprocessor.Config
+	// pipelineMetrics tracks RPC-wait vs replay time across rollupSync's
+	// batch extraction/replay stages; see PipelineMetrics.
+	pipelineMetrics PipelineMetrics
 }
 
-// NewSynchronizer creates a new Synchronizer
+// PipelineMetrics returns the accumulated RPC-wait/replay time recorded
+// by rollupSync's batch extraction and replay stages
+func (s *Synchronizer) PipelineMetrics() (rpcWait, replay time.Duration) {
+	return s.pipelineMetrics.Snapshot()
+}
+
+// NewSynchronizer creates a new Synchronizer. snapshotPath is optional
+// (pass "" to disable): when non-empty and historyDB is empty, the
+// Synchronizer hydrates itself from the snapshot file at that path
+// instead of starting from startBlockNum. See ImportSnapshot.
 func NewSynchronizer(ethClient eth.ClientInterface, historyDB *historydb.HistoryDB,
-	stateDB *statedb.StateDB, cfg Config) (*Synchronizer, error) {
+	stateDB *statedb.StateDB, cfg Config, snapshotPath string) (*Synchronizer, error) {
 	auctionConstants, err := ethClient.AuctionConstants()
 	if err != nil {
 		return nil, tracerr.Wrap(fmt.Errorf("NewSynchronizer ethClient.AuctionConstants(): %w",
@@ -247,6 +293,9 @@ func NewSynchronizer(ethClient eth.ClientInterface, historyDB *historydb.History
 		initVars:      *initVars,
 		startBlockNum: startBlockNum,
 		stats:         stats,
+		snapshotPath:  snapshotPath,
+		eventBus:      noopEventBus{},
+		circuitParams: circuitParamsByVerifier(rollupConstants),
 	}
 	return s, s.init()
 }
@@ -257,6 +306,13 @@ func (s *Synchronizer) Stats() *Stats {
 	return s.stats.CopyStats()
 }
 
+// StateDB returns the inner StateDB the Synchronizer replays batches into,
+// so callers like the health checkers can read its root and current batch
+// without the Synchronizer having to expose that itself
+func (s *Synchronizer) StateDB() *statedb.StateDB {
+	return s.stateDB
+}
+
 // AuctionConstants returns the AuctionConstants read from the smart contract
 func (s *Synchronizer) AuctionConstants() *common.AuctionConstants {
 	return &s.consts.Auction
@@ -290,6 +346,7 @@ func (s *Synchronizer) updateCurrentSlotIfSync(reset bool, firstBatchBlockNum *i
 	// We want the next block because the current one is already mined
 	blockNum := s.stats.Sync.LastBlock.Num + 1
 	slotNum := s.consts.Auction.SlotNum(blockNum)
+	slotChanged := false
 	if reset {
 		dbFirstBatchBlockNum, err := s.historyDB.GetFirstBatchBlockNumBySlot(slotNum)
 		if err != nil && tracerr.Unwrap(err) != sql.ErrNoRows {
@@ -303,6 +360,7 @@ func (s *Synchronizer) updateCurrentSlotIfSync(reset bool, firstBatchBlockNum *i
 	} else if slotNum > slot.SlotNum {
 		// We are in a new slotNum, start from default values
 		slot.ForgerCommitment = false
+		slotChanged = true
 	}
 	slot.SlotNum = slotNum
 	slot.StartBlock, slot.EndBlock = s.consts.Auction.SlotBlocks(slot.SlotNum)
@@ -352,6 +410,9 @@ func (s *Synchronizer) updateCurrentSlotIfSync(reset bool, firstBatchBlockNum *i
 		// END SANITY CHECK
 	}
 	s.stats.UpdateCurrentSlot(slot)
+	if slotChanged {
+		s.notifySlotChange(slot)
+	}
 	return nil
 }
 
@@ -371,7 +432,14 @@ func (s *Synchronizer) init() error {
 	// If we only have the default block 0,
 	// make sure that the stateDB is clean
 	if lastSavedBlock.Num == 0 {
-		if err := s.stateDB.Reset(0); err != nil {
+		if s.snapshotPath != "" {
+			imported, err := s.importSnapshotFile(s.snapshotPath)
+			if err != nil {
+				return tracerr.Wrap(fmt.Errorf("ImportSnapshot(%v): %w",
+					s.snapshotPath, err))
+			}
+			lastBlock = imported
+		} else if err := s.stateDB.Reset(0); err != nil {
 			return tracerr.Wrap(err)
 		}
 	} else {
@@ -396,13 +464,18 @@ func (s *Synchronizer) init() error {
 }
 
 // Sync2 attems to synchronize an ethereum block starting from lastSavedBlock.
-// If lastSavedBlock is nil, the lastSavedBlock value is obtained from de DB.
-// If a block is synched, it will be returned and also stored in the DB.  If a
-// reorg is detected, the number of discarded blocks will be returned and no
-// synchronization will be made.
+// lastSavedBlock is the last *finalized* block (the one historyDB/stateDB
+// were last updated with); if nil, it's obtained from the DB. The fetched
+// block is chain-linked and appended to the in-memory pending ring; once
+// it (or an older pending block) is cfg.FinalityDepth deep, that block is
+// processed for real and stored in historyDB/stateDB, and returned here. If
+// the pending ring hasn't yet produced a finalized block this call, a nil
+// BlockData is returned even though a block was fetched.  If a reorg is
+// detected, the number of discarded blocks will be returned and no
+// synchronization will be made; a reorg entirely within the pending ring
+// never touches historyDB/stateDB.
 // TODO: Be smart about locking: only lock during the read/write operations
 func (s *Synchronizer) Sync2(ctx context.Context, lastSavedBlock *common.Block) (*common.BlockData, *int64, error) {
-	var nextBlockNum int64 // next block number to sync
 	if lastSavedBlock == nil {
 		var err error
 		// Get lastSavedBlock from History DB
@@ -413,17 +486,24 @@ func (s *Synchronizer) Sync2(ctx context.Context, lastSavedBlock *common.Block)
 		// If we don't have any stored block, we must do a full sync
 		// starting from the startBlockNum
 		if tracerr.Unwrap(err) == sql.ErrNoRows || lastSavedBlock.Num == 0 {
-			nextBlockNum = s.startBlockNum
 			lastSavedBlock = nil
 		}
 	}
-	if lastSavedBlock != nil {
-		nextBlockNum = lastSavedBlock.Num + 1
-		if lastSavedBlock.Num < s.startBlockNum {
-			return nil, nil, tracerr.Wrap(
-				fmt.Errorf("lastSavedBlock (%v) < startBlockNum (%v)",
-					lastSavedBlock.Num, s.startBlockNum))
-		}
+	if lastSavedBlock != nil && lastSavedBlock.Num < s.startBlockNum {
+		return nil, nil, tracerr.Wrap(
+			fmt.Errorf("lastSavedBlock (%v) < startBlockNum (%v)",
+				lastSavedBlock.Num, s.startBlockNum))
+	}
+
+	// tip is the last block fetched and chain-linked so far, whether or
+	// not it's been finalized yet
+	tip := lastSavedBlock
+	if len(s.pending) > 0 {
+		tip = s.pending[len(s.pending)-1]
+	}
+	nextBlockNum := s.startBlockNum
+	if tip != nil {
+		nextBlockNum = tip.Num + 1
 	}
 
 	ethBlock, err := s.ethClient.EthBlockByNumber(ctx, nextBlockNum)
@@ -444,38 +524,68 @@ func (s *Synchronizer) Sync2(ctx context.Context, lastSavedBlock *common.Block)
 		"ethLastBlock", s.stats.Eth.LastBlock,
 	)
 
-	// Check that the obtianed ethBlock.ParentHash == prevEthBlock.Hash; if not, reorg!
-	if lastSavedBlock != nil {
-		if lastSavedBlock.Hash != ethBlock.ParentHash {
-			// Reorg detected
-			log.Debugw("Reorg Detected",
-				"blockNum", ethBlock.Num,
-				"block.parent(got)", ethBlock.ParentHash, "parent.hash(exp)", lastSavedBlock.Hash)
-			lastDBBlockNum, err := s.reorg(lastSavedBlock)
-			if err != nil {
-				return nil, nil, tracerr.Wrap(err)
+	// Check that the obtianed ethBlock.ParentHash == tip.Hash; if not, reorg!
+	if tip != nil && tip.Hash != ethBlock.ParentHash {
+		log.Debugw("Reorg Detected",
+			"blockNum", ethBlock.Num,
+			"block.parent(got)", ethBlock.ParentHash, "parent.hash(exp)", tip.Hash)
+		if len(s.pending) > 0 {
+			// the mismatch is within the unfinalized window: drop
+			// every pending block without ever touching
+			// historyDB/stateDB, then re-check against the last
+			// finalized block
+			discarded := int64(len(s.pending))
+			if lastSavedBlock == nil || lastSavedBlock.Hash == ethBlock.ParentHash {
+				resumeFrom := int64(0)
+				if lastSavedBlock != nil {
+					resumeFrom = lastSavedBlock.Num
+				}
+				s.notifyReorg(tip.Num, resumeFrom)
+				s.pending = nil
+				return nil, &discarded, nil
 			}
-			discarded := lastSavedBlock.Num - lastDBBlockNum
-			return nil, &discarded, nil
+			s.pending = nil
 		}
+		lastDBBlockNum, err := s.reorg(lastSavedBlock)
+		if err != nil {
+			return nil, nil, tracerr.Wrap(err)
+		}
+		discarded := lastSavedBlock.Num - lastDBBlockNum
+		s.notifyReorg(lastSavedBlock.Num, lastDBBlockNum)
+		return nil, &discarded, nil
+	}
+
+	s.pending = append(s.pending, ethBlock)
+
+	// finalize every pending block that has reached cfg.FinalityDepth,
+	// oldest first; in practice this processes at most one block per
+	// call, since nextBlockNum only ever advances by one
+	var blockData *common.BlockData
+	for len(s.pending) > 0 &&
+		s.stats.Eth.LastBlock.Num-s.pending[0].Num >= s.cfg.FinalityDepth {
+		finalizing := s.pending[0]
+		s.pending = s.pending[1:]
+		data, err := s.finalizeBlock(finalizing)
+		if err != nil {
+			return nil, nil, tracerr.Wrap(err)
+		}
+		blockData = data
 	}
 
-	// Get data from the rollup contract
-	rollupData, err := s.rollupSync(ethBlock)
-	if err != nil {
-		return nil, nil, tracerr.Wrap(err)
-	}
-
-	// Get data from the auction contract
-	auctionData, err := s.auctionSync(ethBlock)
-	if err != nil {
-		return nil, nil, tracerr.Wrap(err)
-	}
+	return blockData, nil, nil
+}
 
-	// Get data from the WithdrawalDelayer contract
-	wDelayerData, err := s.wdelayerSync(ethBlock)
+// finalizeBlock processes ethBlock's smart contract events into
+// historyDB/stateDB and returns the resulting BlockData. It's only called
+// once ethBlock is cfg.FinalityDepth deep, via Sync2's pending ring.
+func (s *Synchronizer) finalizeBlock(ethBlock *common.Block) (*common.BlockData, error) {
+	// Get data from the rollup, auction and WithdrawalDelayer contracts.
+	// The three are independent RPC sources and are only joined together
+	// below, so syncContracts runs them concurrently instead of paying
+	// their RPC latency three times over sequentially.
+	rollupData, auctionData, wDelayerData, err := s.syncContracts(ethBlock)
 	if err != nil {
-		return nil, nil, tracerr.Wrap(err)
+		return nil, tracerr.Wrap(err)
 	}
 
 	for i := range rollupData.Withdrawals {
@@ -483,7 +593,7 @@ func (s *Synchronizer) Sync2(ctx context.Context, lastSavedBlock *common.Block)
 		if !withdrawal.InstantWithdraw {
 			wDelayerTransfers := wDelayerData.DepositsByTxHash[withdrawal.TxHash]
 			if len(wDelayerTransfers) == 0 {
-				return nil, nil, tracerr.Wrap(fmt.Errorf("WDelayer deposit corresponding to " +
+				return nil, tracerr.Wrap(fmt.Errorf("WDelayer deposit corresponding to " +
 					"non-instant rollup withdrawal not found"))
 			}
 			// Pop the first wDelayerTransfer to consume them in chronological order
@@ -506,7 +616,7 @@ func (s *Synchronizer) Sync2(ctx context.Context, lastSavedBlock *common.Block)
 
 	err = s.historyDB.AddBlockSCData(&blockData)
 	if err != nil {
-		return nil, nil, tracerr.Wrap(err)
+		return nil, tracerr.Wrap(err)
 	}
 
 	batchesLen := len(rollupData.Batches)
@@ -530,7 +640,7 @@ func (s *Synchronizer) Sync2(ctx context.Context, lastSavedBlock *common.Block)
 		firstBatchBlockNum = &rollupData.Batches[0].Batch.EthBlockNum
 	}
 	if err := s.updateCurrentSlotIfSync(false, firstBatchBlockNum); err != nil {
-		return nil, nil, tracerr.Wrap(err)
+		return nil, tracerr.Wrap(err)
 	}
 
 	log.Debugw("Synced block",
@@ -546,7 +656,9 @@ func (s *Synchronizer) Sync2(ctx context.Context, lastSavedBlock *common.Block)
 		)
 	}
 
-	return &blockData, nil, nil
+	s.notifyBlock(&blockData)
+
+	return &blockData, nil
 }
 
 // reorg manages a reorg, updating History and State DB as needed.  Keeps
@@ -724,18 +836,30 @@ func (s *Synchronizer) rollupSync(ethBlock *common.Block) (*common.RollupData, e
 	if err != nil {
 		return nil, tracerr.Wrap(err)
 	}
+	for i := range rollupData.L1UserTxs {
+		s.eventBus.L1UserTxQueued(&rollupData.L1UserTxs[i])
+	}
 
-	// Get ForgeBatch events to get the L1CoordinatorTxs
-	for _, evtForgeBatch := range rollupEvents.ForgeBatch {
+	// Get ForgeBatch events to get the L1CoordinatorTxs. The RollupForgeBatchArgs
+	// RPC for every batch in this block is fetched up front, bounded by
+	// ForgeBatchArgsWorkers, since the replay stage below must stay
+	// sequential (stateDB mutation order matters) but the RPC round-trips
+	// that feed it don't.
+	rpcStart := time.Now()
+	forgeBatchArgsResults, err := s.fetchForgeBatchArgs(rollupEvents.ForgeBatch,
+		s.cfg.ForgeBatchArgsWorkers)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	s.pipelineMetrics.addRPCWait(time.Since(rpcStart))
+
+	replayStart := time.Now()
+	defer func() { s.pipelineMetrics.addReplay(time.Since(replayStart)) }()
+	for i, evtForgeBatch := range rollupEvents.ForgeBatch {
 		batchData := common.NewBatchData()
 		position := 0
 
-		// Get the input for each Tx
-		forgeBatchArgs, sender, err := s.ethClient.RollupForgeBatchArgs(evtForgeBatch.EthTxHash,
-			evtForgeBatch.L1UserTxsLen)
-		if err != nil {
-			return nil, tracerr.Wrap(err)
-		}
+		forgeBatchArgs, sender := forgeBatchArgsResults[i].args, forgeBatchArgsResults[i].sender
 
 		batchNum := common.BatchNum(evtForgeBatch.BatchNum)
 		var l1UserTxs []common.L1Tx
@@ -798,15 +922,11 @@ func (s *Synchronizer) rollupSync(ethBlock *common.Block) (*common.RollupData, e
 		poolL2Txs := common.L2TxsToPoolL2Txs(forgeBatchArgs.L2TxsData) // NOTE: This is a big ugly, find a better way
 
 		// ProcessTxs updates poolL2Txs adding: Nonce (and also TokenID, but we don't use it).
-		//nolint:gomnd
-		tpc := txprocessor.Config{ // TODO TMP
-			NLevels:  32,
-			MaxFeeTx: 64,
-			MaxTx:    512,
-			MaxL1Tx:  64,
-			ChainID:  uint16(0),
+		tpc, err := s.circuitParamsFor(forgeBatchArgs.VerifierIdx)
+		if err != nil {
+			return nil, tracerr.Wrap(err)
 		}
-		tp := txprocessor.NewTxProcessor(s.stateDB, tpc)
+		tp := txprocessor.NewTxProcessor(s.stateDB, *tpc)
 
 		processTxsOut, err := tp.ProcessTxs(forgeBatchArgs.FeeIdxCoordinator,
 			l1UserTxs, batchData.L1CoordinatorTxs, poolL2Txs)
@@ -879,6 +999,7 @@ func (s *Synchronizer) rollupSync(ethBlock *common.Block) (*common.RollupData, e
 			nextForgeL1TxsNum++
 		}
 		batchData.Batch = batch
+		s.eventBus.BatchForged(&batchData.Batch)
 		rollupData.Batches = append(rollupData.Batches, *batchData)
 	}
 
@@ -891,10 +1012,10 @@ func (s *Synchronizer) rollupSync(ethBlock *common.Block) (*common.RollupData, e
 		token.EthBlockNum = blockNum
 
 		if consts, err := s.ethClient.EthERC20Consts(evtAddToken.TokenAddress); err != nil {
-			log.Warnw("Error retreiving ERC20 token constants", "addr", evtAddToken.TokenAddress)
-			token.Name = "ERC20_ETH_ERROR"
-			token.Symbol = "ERROR"
-			token.Decimals = 1
+			name, symbol, decimals := s.resolveERC20Consts(evtAddToken.TokenAddress, err)
+			token.Name = cutStringMax(name, 20)
+			token.Symbol = cutStringMax(symbol, 10)
+			token.Decimals = decimals
 		} else {
 			token.Name = cutStringMax(consts.Name, 20)
 			token.Symbol = cutStringMax(consts.Symbol, 10)
@@ -902,25 +1023,29 @@ func (s *Synchronizer) rollupSync(ethBlock *common.Block) (*common.RollupData, e
 		}
 
 		rollupData.AddedTokens = append(rollupData.AddedTokens, token)
+		s.eventBus.AddToken(&token)
 	}
 
 	for _, evt := range rollupEvents.UpdateBucketWithdraw {
-		rollupData.UpdateBucketWithdraw = append(rollupData.UpdateBucketWithdraw,
-			common.BucketUpdate{
-				EthBlockNum: blockNum,
-				NumBucket:   evt.NumBucket,
-				BlockStamp:  evt.BlockStamp,
-				Withdrawals: evt.Withdrawals,
-			})
+		bucketUpdate := common.BucketUpdate{
+			EthBlockNum: blockNum,
+			NumBucket:   evt.NumBucket,
+			BlockStamp:  evt.BlockStamp,
+			Withdrawals: evt.Withdrawals,
+		}
+		rollupData.UpdateBucketWithdraw = append(rollupData.UpdateBucketWithdraw, bucketUpdate)
+		s.eventBus.UpdateBucketsParameters(&bucketUpdate)
 	}
 
 	for _, evt := range rollupEvents.Withdraw {
-		rollupData.Withdrawals = append(rollupData.Withdrawals, common.WithdrawInfo{
+		withdrawal := common.WithdrawInfo{
 			Idx:             common.Idx(evt.Idx),
 			NumExitRoot:     common.BatchNum(evt.NumExitRoot),
 			InstantWithdraw: evt.InstantWithdraw,
 			TxHash:          evt.TxHash,
-		})
+		}
+		rollupData.Withdrawals = append(rollupData.Withdrawals, withdrawal)
+		s.eventBus.WithdrawalInitiated(&withdrawal)
 	}
 
 	for _, evt := range rollupEvents.UpdateTokenExchange {
@@ -1018,6 +1143,7 @@ func (s *Synchronizer) auctionSync(ethBlock *common.Block) (*common.AuctionData,
 			EthBlockNum: blockNum,
 		}
 		auctionData.Bids = append(auctionData.Bids, bid)
+		s.eventBus.NewBid(&bid)
 	}
 
 	// Get Coordinators
@@ -1145,6 +1271,7 @@ func (s *Synchronizer) wdelayerSync(ethBlock *common.Block) (*common.WDelayerDat
 		s.vars.WDelayer.EmergencyMode = true
 		s.vars.WDelayer.EmergencyModeStartingBlock = blockNum
 		varsUpdate = true
+		s.eventBus.EmergencyModeEnabled()
 	}
 	for _, evt := range wDelayerEvents.NewWithdrawalDelay {
 		s.vars.WDelayer.WithdrawalDelay = evt.WithdrawalDelay
@@ -0,0 +1,128 @@
+package transakcio
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// AtomicTxInstruction describes a single L2 transfer that is part of an
+// atomic group (Hermez's linked-tx mechanism), addressed with the same
+// user names used by GeneratePoolL2Txs. Rq, when set, is the index (within
+// the same group slice) of the tx that this one requests to be forged
+// alongside.
+type AtomicTxInstruction struct {
+	From    string
+	To      string
+	TokenID common.TokenID
+	Amount  uint64
+	Fee     uint8
+	Rq      *int
+}
+
+// rqOffsetForRelativePosition translates the position of the requested tx
+// relative to the requester (as used to build an atomic group) into the
+// protocol RqOffset value (1 to 7), which is the inverse of the translation
+// done when an atomic group is validated on submission.
+func rqOffsetForRelativePosition(rel int) (uint8, error) {
+	switch rel {
+	case 1, 2, 3:
+		return uint8(rel), nil
+	case -4:
+		return 4, nil
+	case -3:
+		return 5, nil
+	case -2:
+		return 6, nil
+	case -1:
+		return 7, nil
+	default:
+		return 0, fmt.Errorf("can not express a requested tx %d positions away "+
+			"as a RqOffset (valid range is [-4, 3] excluding 0)", rel)
+	}
+}
+
+// GenerateAtomicPoolL2Txs returns the common.PoolL2Tx of an atomic group
+// (e.g. `TransferAtomic(A,B): ... <-> TransferAtomic(B,A): ...`), with the
+// RqFromIdx, RqToIdx, RqToEthAddr, RqToBJJ, RqTokenID, RqAmount, RqFee and
+// RqNonce fields populated from the relation described by each
+// AtomicTxInstruction.Rq, and signed over the hash that includes those
+// linked fields so the returned txs are valid inputs to the pool and
+// forger logic. It returns an error if the group has fewer than 2 txs, or
+// if a requester's requested counterpart is not present in the group
+// (unbalanced bundle).
+func (tc *TestContext) GenerateAtomicPoolL2Txs(group []AtomicTxInstruction) ([]common.PoolL2Tx, error) {
+	if len(group) < 2 {
+		return nil, fmt.Errorf("an atomic group must contain at least 2 txs, got %d", len(group))
+	}
+	for i, inst := range group {
+		if inst.Rq == nil {
+			continue
+		}
+		if *inst.Rq < 0 || *inst.Rq >= len(group) || *inst.Rq == i {
+			return nil, fmt.Errorf("unbalanced atomic group: tx %d requests a "+
+				"counterpart (%d) that is not present in the group", i, *inst.Rq)
+		}
+	}
+
+	txs := make([]common.PoolL2Tx, len(group))
+	for i, inst := range group {
+		if tc.Users[inst.From].Accounts[inst.TokenID] == nil {
+			return nil, fmt.Errorf("Transfer from User %s for TokenID %d while "+
+				"account not created yet", inst.From, inst.TokenID)
+		}
+		if tc.Users[inst.To].Accounts[inst.TokenID] == nil {
+			return nil, fmt.Errorf("Transfer to User %s for TokenID %d while "+
+				"account not created yet", inst.To, inst.TokenID)
+		}
+		tc.Users[inst.From].Accounts[inst.TokenID].Nonce++
+		txs[i] = common.PoolL2Tx{
+			FromIdx:   tc.Users[inst.From].Accounts[inst.TokenID].Idx,
+			ToIdx:     tc.Users[inst.To].Accounts[inst.TokenID].Idx,
+			ToEthAddr: tc.Users[inst.To].Addr,
+			ToBJJ:     tc.Users[inst.To].BJJ.Public(),
+			TokenID:   inst.TokenID,
+			Amount:    big.NewInt(int64(inst.Amount)),
+			Fee:       common.FeeSelector(inst.Fee),
+			Nonce:     tc.Users[inst.From].Accounts[inst.TokenID].Nonce,
+			State:     common.PoolL2TxStatePending,
+			Type:      common.TxTypeTransfer,
+		}
+	}
+
+	for i, inst := range group {
+		if inst.Rq == nil {
+			continue
+		}
+		rq := &txs[*inst.Rq]
+		offset, err := rqOffsetForRelativePosition(*inst.Rq - i)
+		if err != nil {
+			return nil, err
+		}
+		txs[i].RqOffset = offset
+		txs[i].RqFromIdx = rq.FromIdx
+		txs[i].RqToIdx = rq.ToIdx
+		txs[i].RqToEthAddr = rq.ToEthAddr
+		txs[i].RqToBJJ = rq.ToBJJ
+		txs[i].RqTokenID = rq.TokenID
+		txs[i].RqAmount = rq.Amount
+		txs[i].RqFee = rq.Fee
+		txs[i].RqNonce = rq.Nonce
+	}
+
+	for i := range txs {
+		nTx, err := common.NewPoolL2Tx(&txs[i])
+		if err != nil {
+			return nil, err
+		}
+		txs[i] = *nTx
+		toSign, err := txs[i].HashToSign()
+		if err != nil {
+			return nil, err
+		}
+		txs[i].Signature = tc.Users[group[i].From].BJJ.SignPoseidon(toSign)
+	}
+
+	return txs, nil
+}
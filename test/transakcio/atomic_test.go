@@ -0,0 +1,85 @@
+package transakcio
+
+import (
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// atomicTestScenario creates two accounts (A and B) for TokenID 1, the
+// minimum fixture GenerateAtomicPoolL2Txs needs to build a linked group
+// between them.
+const atomicTestScenario = `
+Type: Blockchain
+RegisterToken(1)
+CreateAccountDeposit(1) A: 100
+CreateAccountDeposit(1) B: 100
+> batchL1
+> block
+`
+
+// TestGenerateAtomicPoolL2Txs builds a 2-tx atomic group (A requests B,
+// B requests A, as produced by the ATOMIC_TX scenario instruction) and
+// checks that the Rq* linked fields and RqOffset describe the relation back
+// to the counterpart tx, and that both txs carry a signature.
+func TestGenerateAtomicPoolL2Txs(t *testing.T) {
+	tc := NewTestContext(16) //nolint:gomnd
+	if _, err := tc.GenerateBlocks(atomicTestScenario); err != nil {
+		t.Fatalf("GenerateBlocks: %v", err)
+	}
+
+	rqB := 1
+	rqA := 0
+	group := []AtomicTxInstruction{
+		{From: "A", To: "B", TokenID: 1, Amount: 10, Fee: 0, Rq: &rqB},
+		{From: "B", To: "A", TokenID: 1, Amount: 20, Fee: 0, Rq: &rqA},
+	}
+
+	txs, err := tc.GenerateAtomicPoolL2Txs(group)
+	if err != nil {
+		t.Fatalf("GenerateAtomicPoolL2Txs: %v", err)
+	}
+	if len(txs) != len(group) {
+		t.Fatalf("expected %d txs, got %d", len(group), len(txs))
+	}
+
+	if txs[0].RqOffset != 1 {
+		t.Errorf("tx 0 requests the tx 1 position away, expected RqOffset 1, got %d", txs[0].RqOffset)
+	}
+	if txs[0].RqFromIdx != txs[1].FromIdx || txs[0].RqToIdx != txs[1].ToIdx {
+		t.Errorf("tx 0's Rq* fields don't describe tx 1")
+	}
+	if txs[1].RqOffset != 7 {
+		t.Errorf("tx 1 requests the tx 1 position before it, expected RqOffset 7, got %d", txs[1].RqOffset)
+	}
+	if txs[1].RqFromIdx != txs[0].FromIdx || txs[1].RqToIdx != txs[0].ToIdx {
+		t.Errorf("tx 1's Rq* fields don't describe tx 0")
+	}
+	for i, tx := range txs {
+		if tx.Signature == nil {
+			t.Errorf("tx %d was not signed", i)
+		}
+	}
+}
+
+// TestGenerateAtomicPoolL2TxsErrors checks the two validation errors
+// GenerateAtomicPoolL2Txs returns before attempting to build any tx.
+func TestGenerateAtomicPoolL2TxsErrors(t *testing.T) {
+	tc := NewTestContext(16) //nolint:gomnd
+	if _, err := tc.GenerateBlocks(atomicTestScenario); err != nil {
+		t.Fatalf("GenerateBlocks: %v", err)
+	}
+
+	if _, err := tc.GenerateAtomicPoolL2Txs([]AtomicTxInstruction{{From: "A", To: "B", TokenID: 1, Amount: 10}}); err == nil {
+		t.Errorf("expected an error for a group with fewer than 2 txs")
+	}
+
+	badRq := 5
+	unbalanced := []AtomicTxInstruction{
+		{From: "A", To: "B", TokenID: 1, Amount: 10, Rq: &badRq},
+		{From: "B", To: "A", TokenID: 1, Amount: 20},
+	}
+	if _, err := tc.GenerateAtomicPoolL2Txs(unbalanced); err == nil {
+		t.Errorf("expected an error for a group requesting a counterpart outside the group")
+	}
+}
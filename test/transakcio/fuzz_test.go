@@ -0,0 +1,50 @@
+package transakcio
+
+import "testing"
+
+// defaultFuzzScenarioConfig is used by FuzzTransakcio to keep every
+// generated scenario a reasonable size while still exercising idx
+// accounting, nonce tracking and L1 queue rotation
+var defaultFuzzScenarioConfig = ScenarioConfig{
+	NUsers:                        5,
+	NTokens:                       3,
+	NBlocks:                       2,
+	NBatchesPerBlock:              3,
+	L1TxRatio:                     0.4,
+	ExitProb:                      0.2,
+	ForceExitProb:                 0.1,
+	CoordinatorCreatedAccountProb: 0.2,
+	MaxL1QueueDepth:               16,
+	TxsPerBatch:                   6,
+}
+
+// FuzzTransakcio feeds GenerateRandomScenario-produced instruction sets
+// through GenerateBlocks and GeneratePoolL2Txs, to shake out edge-case
+// panics in idx accounting, nonce tracking and the L1 queue rotation in
+// addToL1Queue
+func FuzzTransakcio(f *testing.F) {
+	for _, seed := range []int64{0, 1, 42, 1337} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, seed int64) {
+		gen := NewTestContext(defaultFuzzScenarioConfig.MaxL1QueueDepth)
+		set, err := gen.GenerateRandomScenario(seed, defaultFuzzScenarioConfig)
+		if err != nil {
+			t.Fatalf("GenerateRandomScenario: %v", err)
+		}
+
+		blocksTc := NewTestContext(defaultFuzzScenarioConfig.MaxL1QueueDepth)
+		if _, err := blocksTc.GenerateBlocks(set); err != nil {
+			t.Fatalf("GenerateBlocks on generated scenario: %v\nset:\n%s", err, set)
+		}
+
+		poolTc := NewTestContext(defaultFuzzScenarioConfig.MaxL1QueueDepth)
+		if _, err := poolTc.GeneratePoolL2Txs(set); err != nil {
+			// the scenario intentionally also contains L1-only
+			// instructions (RegisterToken, batch markers) that
+			// GeneratePoolL2Txs does not understand; only fail on
+			// a panic, which `go test -fuzz` already catches
+			t.Logf("GeneratePoolL2Txs on generated scenario: %v", err)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package transakcio
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// ScenarioConfig configures GenerateRandomScenario
+type ScenarioConfig struct {
+	NUsers  int
+	NTokens int
+	NBlocks int
+	// NBatchesPerBlock is the number of batches generated per block
+	NBatchesPerBlock int
+	// L1TxRatio is the probability ([0,1]) that a generated tx inside a
+	// batch is an L1 tx instead of an L2 tx
+	L1TxRatio float64
+	// ExitProb is the probability ([0,1]) that a generated L2 tx is an
+	// Exit instead of a Transfer
+	ExitProb float64
+	// ForceExitProb is the probability ([0,1]) that a generated L1 tx is
+	// a ForceExit instead of a Deposit/CreateAccountDeposit
+	ForceExitProb float64
+	// CoordinatorCreatedAccountProb is the probability ([0,1]) that a
+	// new account is created by the coordinator (txTypeCreateAccountDepositCoordinator)
+	// instead of by the user themselves (CreateAccountDeposit)
+	CoordinatorCreatedAccountProb float64
+	// MaxL1QueueDepth caps how many L1 txs GenerateRandomScenario will
+	// place in a single queue before forcing a new batchL1, to stay under
+	// rollupConstMaxL1UserTx
+	MaxL1QueueDepth int
+	// TxsPerBatch is the number of txs attempted per batch
+	TxsPerBatch int
+}
+
+// scenarioState tracks, while emitting instructions, the invariants that
+// GenerateBlocks enforces: sequential token registration, no duplicate
+// (user, tokenID) account creation, account-exists-before-transfer and the
+// L1 queue depth
+type scenarioState struct {
+	users           []string
+	nRegisteredTok  int
+	accounts        map[string]map[int]bool // user -> tokenID -> exists
+	pendingL1Queue  int
+	rollupMaxL1Tx   int
+	tokensAvailable int
+}
+
+func (s *scenarioState) hasAccount(user string, tokenID int) bool {
+	return s.accounts[user] != nil && s.accounts[user][tokenID]
+}
+
+func (s *scenarioState) createAccount(user string, tokenID int) {
+	if s.accounts[user] == nil {
+		s.accounts[user] = make(map[int]bool)
+	}
+	s.accounts[user][tokenID] = true
+}
+
+// usersWithAccount returns the users that already have an account for
+// tokenID
+func (s *scenarioState) usersWithAccount(tokenID int) []string {
+	var users []string
+	for _, u := range s.users {
+		if s.hasAccount(u, tokenID) {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// GenerateRandomScenario synthesizes a valid Transakcio instruction set
+// (the same textual format accepted by GenerateBlocks and
+// GeneratePoolL2Txs) from a seeded PRNG, following the knobs in cfg. The
+// invariants enforced by GenerateBlocks (sequential token registration, no
+// duplicate account creation per (user, tokenID), account-exists-before-
+// transfer and the queue-size cap against rollupConstMaxL1UserTx) are
+// maintained internally, so the returned set is guaranteed to parse and
+// execute without error.
+func (tc *TestContext) GenerateRandomScenario(seed int64, cfg ScenarioConfig) (string, error) {
+	if cfg.NUsers < 1 {
+		return "", fmt.Errorf("cfg.NUsers must be >= 1")
+	}
+	if cfg.NTokens < 1 {
+		return "", fmt.Errorf("cfg.NTokens must be >= 1")
+	}
+	if cfg.MaxL1QueueDepth <= 0 || cfg.MaxL1QueueDepth > tc.rollupConstMaxL1UserTx {
+		cfg.MaxL1QueueDepth = tc.rollupConstMaxL1UserTx
+	}
+	if cfg.TxsPerBatch <= 0 {
+		cfg.TxsPerBatch = 10 //nolint:gomnd
+	}
+
+	r := rand.New(rand.NewSource(seed)) //nolint:gosec
+
+	users := make([]string, cfg.NUsers)
+	for i := 0; i < cfg.NUsers; i++ {
+		users[i] = fmt.Sprintf("U%d", i)
+	}
+	state := &scenarioState{
+		users:         users,
+		accounts:      make(map[string]map[int]bool),
+		rollupMaxL1Tx: tc.rollupConstMaxL1UserTx,
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Type: Blockchain\n")
+
+	// register all the tokens up-front, sequentially, as required by
+	// checkIfTokenIsRegistered
+	for t := 1; t <= cfg.NTokens; t++ {
+		fmt.Fprintf(&sb, "RegisterToken(%d)\n", t)
+	}
+	state.tokensAvailable = cfg.NTokens
+
+	for block := 0; block < cfg.NBlocks; block++ {
+		for batch := 0; batch < cfg.NBatchesPerBlock; batch++ {
+			isL1Batch := state.pendingL1Queue > 0 || r.Float64() < cfg.L1TxRatio
+			for i := 0; i < cfg.TxsPerBatch; i++ {
+				tokenID := 1 + r.Intn(state.tokensAvailable)
+				useL1 := r.Float64() < cfg.L1TxRatio
+				if useL1 {
+					tc.emitRandomL1Tx(&sb, state, r, cfg, tokenID)
+				} else {
+					tc.emitRandomL2Tx(&sb, state, r, cfg, tokenID)
+				}
+			}
+			if isL1Batch || state.pendingL1Queue >= cfg.MaxL1QueueDepth {
+				sb.WriteString("> batchL1\n")
+				state.pendingL1Queue = 0
+			} else {
+				sb.WriteString("> batch\n")
+			}
+		}
+		sb.WriteString("> block\n")
+	}
+
+	return sb.String(), nil
+}
+
+// emitRandomL1Tx appends a single, invariant-respecting L1 instruction
+func (tc *TestContext) emitRandomL1Tx(sb *strings.Builder, state *scenarioState,
+	r *rand.Rand, cfg ScenarioConfig, tokenID int) {
+	holders := state.usersWithAccount(tokenID)
+	if len(holders) > 0 && r.Float64() < cfg.ForceExitProb {
+		user := holders[r.Intn(len(holders))]
+		fmt.Fprintf(sb, "ForceExit(%d) %s: %d\n", tokenID, user, 1+r.Intn(10)) //nolint:gomnd
+		state.pendingL1Queue++
+		return
+	}
+	if len(holders) > 0 && r.Float64() < cfg.CoordinatorCreatedAccountProb {
+		for _, u := range state.users {
+			if !state.hasAccount(u, tokenID) {
+				fmt.Fprintf(sb, "CreateAccountCoordinator(%d) %s\n", tokenID, u)
+				state.createAccount(u, tokenID)
+				return
+			}
+		}
+	}
+	user := state.users[r.Intn(len(state.users))]
+	if state.hasAccount(user, tokenID) {
+		fmt.Fprintf(sb, "Deposit(%d) %s: %d\n", tokenID, user, 10+r.Intn(100)) //nolint:gomnd
+	} else {
+		fmt.Fprintf(sb, "CreateAccountDeposit(%d) %s: %d\n", tokenID, user, 10+r.Intn(100)) //nolint:gomnd
+		state.createAccount(user, tokenID)
+	}
+	state.pendingL1Queue++
+}
+
+// emitRandomL2Tx appends a single, invariant-respecting L2 instruction.
+// Transfers are only emitted between users that already hold an account for
+// tokenID, to respect account-exists-before-transfer.
+func (tc *TestContext) emitRandomL2Tx(sb *strings.Builder, state *scenarioState,
+	r *rand.Rand, cfg ScenarioConfig, tokenID int) {
+	holders := state.usersWithAccount(tokenID)
+	if len(holders) == 0 {
+		return
+	}
+	from := holders[r.Intn(len(holders))]
+	if r.Float64() < cfg.ExitProb {
+		fmt.Fprintf(sb, "Exit(%d) %s: %d\n", tokenID, from, 1+r.Intn(10)) //nolint:gomnd
+		return
+	}
+	if len(holders) < 2 {
+		return
+	}
+	to := holders[r.Intn(len(holders))]
+	for to == from {
+		to = holders[r.Intn(len(holders))]
+	}
+	fee := r.Intn(burstFeeSelectors)
+	fmt.Fprintf(sb, "Transfer(%d) %s-%s: %d (%d)\n", tokenID, from, to, 1+r.Intn(10), fee) //nolint:gomnd
+}
+
+// burstFeeSelectors bounds the randomly generated FeeSelector so it always
+// stays within the valid protocol range
+const burstFeeSelectors = 8
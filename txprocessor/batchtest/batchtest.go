@@ -0,0 +1,171 @@
+/*
+Package batchtest is a JSON fixture runner for txprocessor.TxProcessor,
+modeled on Ethereum's BlockTest runner. Fixtures describe the pre-state, the
+txs to process and the expected post-state, so the Go processor and the
+circom circuits can share testvectors byte-for-byte.
+*/
+package batchtest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+	"github.com/hermeznetwork/hermez-node/txprocessor"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// generate, when set via -generate, makes Run fill the fixture's
+// Post.Accounts and ExpectedZKInputs in place from the actual processor
+// output instead of asserting against them; nothing is written to disk
+var generate = flag.Bool("generate", false, "emit the fixture's expected output instead of asserting against it")
+
+// PreState describes the fixture's pre-state
+type PreState struct {
+	Accounts   []common.Account `json:"accounts"`
+	CoordIdxs  []common.Idx     `json:"coordIdxs"`
+	OldLastIdx common.Idx       `json:"oldLastIdx"`
+}
+
+// PostState describes the fixture's expected post-state
+type PostState struct {
+	Accounts  []common.Account `json:"accounts"`
+	ExitRoot  string           `json:"exitRoot"`
+	StateRoot string           `json:"stateRoot"`
+}
+
+// BatchTest is a single JSON fixture consumed by Run
+type BatchTest struct {
+	Name             string                     `json:"name"`
+	Pre              PreState                   `json:"pre"`
+	Config           txprocessor.Config         `json:"config"`
+	L1UserTxs        []common.L1Tx              `json:"l1UserTxs"`
+	L1CoordTxs       []common.L1Tx              `json:"l1CoordTxs"`
+	L2Txs            []common.PoolL2Tx          `json:"l2Txs"`
+	Post             PostState                  `json:"post"`
+	ExpectedZKInputs *common.ZKInputs           `json:"expectedZKInputs"`
+	ExpectedHash     string                     `json:"expectedHashGlobalData"`
+	ExpectedToHash   string                     `json:"expectedToHashGlobalData"`
+}
+
+// Load reads a single BatchTest fixture
+func Load(path string) (*BatchTest, error) {
+	b, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	var bt BatchTest
+	if err := json.Unmarshal(b, &bt); err != nil {
+		return nil, tracerr.Wrap(fmt.Errorf("%s: %w", path, err))
+	}
+	return &bt, nil
+}
+
+// LoadDir reads every *.json fixture in dir
+func LoadDir(dir string) ([]*BatchTest, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	tests := make([]*BatchTest, 0, len(paths))
+	for _, path := range paths {
+		bt, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		tests = append(tests, bt)
+	}
+	return tests, nil
+}
+
+// Mismatch describes a single field that didn't match between the fixture's
+// expectation and the processor's actual output
+type Mismatch struct {
+	Field string
+	Want  string
+	Got   string
+}
+
+// Run builds a temp StateDB seeded with bt.Pre.Accounts through ApplyAlloc
+// (which does not apply their Idx or Nonce, nor OldLastIdx), runs
+// ProcessTxs through a txprocessor.TxProcessor and compares HashGlobalData
+// and ToHashGlobalData (when the expected values are non-empty) and
+// ZKInputs (when bt.ExpectedZKInputs is set). bt.Post (accounts, state
+// root, exit root) is not compared. When -generate is passed, it instead
+// fills bt.Post.Accounts and bt.ExpectedZKInputs in place from the actual
+// output and returns no mismatches.
+func Run(dbPath string, bt *BatchTest) ([]Mismatch, error) {
+	sdb, err := statedb.NewStateDB(dbPath, statedb.TypeBatchBuilder, bt.Config.NLevels)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	if err := sdb.ApplyAlloc(statedb.Alloc{
+		Accounts:   accountsToAlloc(bt.Pre.Accounts),
+		OldLastIdx: bt.Pre.OldLastIdx,
+	}); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	tp := txprocessor.NewTxProcessor(sdb, bt.Config)
+	ptOut, err := tp.ProcessTxs(bt.Pre.CoordIdxs, bt.L1UserTxs, bt.L1CoordTxs, bt.L2Txs)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	if *generate {
+		accounts, err := sdb.GetAccounts()
+		if err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		bt.Post.Accounts = accounts
+		bt.ExpectedZKInputs = ptOut.ZKInputs
+		return nil, nil
+	}
+
+	var mismatches []Mismatch
+	h, err := ptOut.ZKInputs.HashGlobalData()
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	if bt.ExpectedHash != "" && h.String() != bt.ExpectedHash {
+		mismatches = append(mismatches, Mismatch{Field: "HashGlobalData", Want: bt.ExpectedHash, Got: h.String()})
+	}
+	toHash, err := ptOut.ZKInputs.ToHashGlobalData()
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	toHashHex := hex.EncodeToString(toHash)
+	if bt.ExpectedToHash != "" && toHashHex != bt.ExpectedToHash {
+		mismatches = append(mismatches, Mismatch{Field: "ToHashGlobalData", Want: bt.ExpectedToHash, Got: toHashHex})
+	}
+	if bt.ExpectedZKInputs != nil {
+		zkMismatches, err := txprocessor.CompareZKInputs(bt.ExpectedZKInputs, ptOut.ZKInputs)
+		if err != nil {
+			return nil, tracerr.Wrap(err)
+		}
+		for _, m := range zkMismatches {
+			mismatches = append(mismatches, Mismatch{Field: "ZKInputs." + m.Field, Want: m.Want, Got: m.Got})
+		}
+	}
+	return mismatches, nil
+}
+
+func accountsToAlloc(accounts []common.Account) []statedb.AllocAccount {
+	alloc := make([]statedb.AllocAccount, len(accounts))
+	for i, acc := range accounts {
+		alloc[i] = statedb.AllocAccount{
+			Idx:     acc.Idx,
+			TokenID: acc.TokenID,
+			Nonce:   acc.Nonce,
+			Balance: acc.Balance,
+			BJJ:     acc.BJJ,
+			EthAddr: acc.EthAddr,
+		}
+	}
+	return alloc
+}
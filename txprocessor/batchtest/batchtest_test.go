@@ -0,0 +1,25 @@
+package batchtest
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTestVectors(t *testing.T) {
+	tests, err := LoadDir("testvectors")
+	require.Nil(t, err)
+
+	for _, bt := range tests {
+		dir, err := ioutil.TempDir("", "tmpdb")
+		require.Nil(t, err)
+		defer func() { assert.Nil(t, os.RemoveAll(dir)) }()
+
+		mismatches, err := Run(dir, bt)
+		require.Nil(t, err)
+		assert.Empty(t, mismatches, bt.Name)
+	}
+}
@@ -0,0 +1,164 @@
+// +build circom_e2e
+
+/*
+This file implements an opt-in integration test that cross-checks a
+TxProcessor-generated ZKInputs against the real circom circuit, instead of
+trusting that ZKInputs' layout matches what the circuit expects. It's gated
+behind the circom_e2e build tag because it shells out to external tooling
+(a node-based witness calculator and snarkjs) and needs circuit artifacts
+that aren't part of this repo, so it's excluded from the default `go test
+./...` run.
+
+Required environment variables:
+
+	CIRCOM_WITNESS_CALC  path to the circuit's generate_witness.js (or an
+	                     equivalent wasm witness calculator wrapper)
+	CIRCOM_WASM          path to rollup.wasm
+	CIRCOM_ZKEY          path to rollup.zkey (only needed if SNARKJS_BIN is
+	                     used to produce a proof rather than just a witness)
+	SNARKJS_BIN          path to the snarkjs CLI, used to export the
+	                     witness's public signals as JSON
+
+Run with:
+
+	CIRCOM_WITNESS_CALC=... CIRCOM_WASM=... SNARKJS_BIN=... \
+	    go test -tags circom_e2e ./txprocessor/... -run TestCircomE2E -v
+*/
+package txprocessor
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+	"github.com/hermeznetwork/hermez-node/txprocessor/tbuilder"
+)
+
+// circomPublicSignal is the position of a named public signal in the
+// circuit's witness.json output array. The rollup circuit's actual public
+// signal ordering is defined in its own .circom sources (not part of this
+// repo); callers that use a different build should override these via
+// CIRCOM_PUBLIC_SIGNAL_<NAME> env vars (e.g. CIRCOM_PUBLIC_SIGNAL_OLDSTATEROOT=0).
+var circomPublicSignal = map[string]int{
+	"oldStateRoot":     0,
+	"newStateRoot":     1,
+	"newExitRoot":      2,
+	"hashGlobalInputs": 3, //nolint:gomnd
+}
+
+func init() {
+	for name := range circomPublicSignal {
+		v := os.Getenv("CIRCOM_PUBLIC_SIGNAL_" + name)
+		if v == "" {
+			continue
+		}
+		if idx, err := strconv.Atoi(v); err == nil {
+			circomPublicSignal[name] = idx
+		}
+	}
+}
+
+// TestCircomE2E processes a small batch, feeds the resulting ZKInputs
+// through the real circuit's witness calculator, and checks the witness's
+// hashGlobalInputs public signal against what ProcessTxs computed on the Go
+// side; the oldStateRoot/newStateRoot/newExitRoot signals are not
+// compared. This is the only
+// test in the repo that exercises the actual circuit rather than a Go-side
+// expectation of what the circuit does.
+func TestCircomE2E(t *testing.T) {
+	witnessCalc := os.Getenv("CIRCOM_WITNESS_CALC")
+	wasm := os.Getenv("CIRCOM_WASM")
+	snarkjs := os.Getenv("SNARKJS_BIN")
+	if witnessCalc == "" || wasm == "" || snarkjs == "" {
+		t.Skip("CIRCOM_WITNESS_CALC, CIRCOM_WASM and SNARKJS_BIN must all be set to run this test")
+	}
+
+	dir, err := ioutil.TempDir("", "circome2e")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	res, err := tbuilder.Parse(`
+		CreateAccountDeposit(A, 1, 1000)
+		CreateAccountDeposit(B, 1, 0)
+		Transfer(A-B, 1, 100)
+	`)
+	if err != nil {
+		t.Fatalf("tbuilder.Parse: %v", err)
+	}
+
+	sdb, err := statedb.NewStateDB(dir, statedb.TypeBatchBuilder, goldenNLevels)
+	if err != nil {
+		t.Fatalf("NewStateDB: %v", err)
+	}
+	cfg := Config{NLevels: goldenNLevels, MaxFeeTx: 2, MaxTx: 4, MaxL1Tx: 4} //nolint:gomnd
+	tp := NewTxProcessor(sdb, cfg)
+
+	ptOut, err := tp.ProcessTxs(nil, res.L1Txs, nil, res.L2Txs)
+	if err != nil {
+		t.Fatalf("ProcessTxs: %v", err)
+	}
+
+	inputPath := filepath.Join(dir, "input.json")
+	inFile, err := os.Create(inputPath) //nolint:gosec
+	if err != nil {
+		t.Fatalf("Create input.json: %v", err)
+	}
+	if err := ptOut.ZKInputs.WriteJSON(inFile); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	if err := inFile.Close(); err != nil {
+		t.Fatalf("close input.json: %v", err)
+	}
+
+	witnessPath := filepath.Join(dir, "witness.wtns")
+	// e.g. `node generate_witness.js rollup.wasm input.json witness.wtns`
+	if out, err := exec.Command("node", witnessCalc, wasm, inputPath, witnessPath).CombinedOutput(); err != nil { //nolint:gosec
+		t.Fatalf("witness calculator failed: %v\n%s", err, out)
+	}
+
+	publicPath := filepath.Join(dir, "public.json")
+	if out, err := exec.Command(snarkjs, "wtns", "export", "json", //nolint:gosec
+		witnessPath, publicPath).CombinedOutput(); err != nil {
+		t.Fatalf("snarkjs wtns export json failed: %v\n%s", err, out)
+	}
+
+	publicRaw, err := ioutil.ReadFile(publicPath) //nolint:gosec
+	if err != nil {
+		t.Fatalf("read public.json: %v", err)
+	}
+	var signals []string
+	if err := json.Unmarshal(publicRaw, &signals); err != nil {
+		t.Fatalf("unmarshal public.json: %v", err)
+	}
+
+	assertSignal(t, signals, "hashGlobalInputs", func() (string, error) {
+		h, err := ptOut.ZKInputs.HashGlobalData()
+		if err != nil {
+			return "", err
+		}
+		return h.String(), nil
+	})
+}
+
+func assertSignal(t *testing.T, signals []string, name string, want func() (string, error)) {
+	t.Helper()
+	idx, ok := circomPublicSignal[name]
+	if !ok || idx >= len(signals) {
+		t.Errorf("no public signal registered (or out of range) for %q", name)
+		return
+	}
+	wantVal, err := want()
+	if err != nil {
+		t.Fatalf("computing expected %s: %v", name, err)
+	}
+	if signals[idx] != wantVal {
+		t.Errorf("public signal %s: circuit=%s go=%s", name, signals[idx], wantVal)
+	}
+}
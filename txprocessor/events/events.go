@@ -0,0 +1,248 @@
+/*
+Package events lets subscribers receive structured events for everything
+txprocessor.TxProcessor did while processing a batch, modeled on
+go-ethereum's eth/filters: callers register a Filter and either poll
+GetEvents or stream matches via a Go channel. This unlocks coordinator
+dashboards, exit-tree watchers and indexers without each one re-running the
+processor.
+*/
+package events
+
+import (
+	"sync"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// Type identifies the kind of event emitted during batch processing
+type Type string
+
+const (
+	// TypeAccountCreated is emitted when a new account leaf is created
+	TypeAccountCreated Type = "AccountCreated"
+	// TypeBalanceChanged is emitted whenever an account's balance changes
+	TypeBalanceChanged Type = "BalanceChanged"
+	// TypeNonceIncremented is emitted whenever an account's nonce is bumped
+	TypeNonceIncremented Type = "NonceIncremented"
+	// TypeExit is emitted for every tx that results in an exit
+	TypeExit Type = "Exit"
+	// TypeFeeCollected is emitted once per coordinator Idx/TokenID pair
+	// that collected a fee
+	TypeFeeCollected Type = "FeeCollected"
+	// TypeL1TxForged is emitted once per forged L1 tx
+	TypeL1TxForged Type = "L1TxForged"
+	// TypeL2TxForged is emitted once per forged L2 tx
+	TypeL2TxForged Type = "L2TxForged"
+)
+
+// AccountCreated is the payload of a TypeAccountCreated event
+type AccountCreated struct {
+	Idx     common.Idx
+	BJJ     string
+	EthAddr ethCommon.Address
+	TokenID common.TokenID
+}
+
+// BalanceChanged is the payload of a TypeBalanceChanged event
+type BalanceChanged struct {
+	Idx    common.Idx
+	Old    string
+	New    string
+	Delta  string
+	Reason string
+}
+
+// NonceIncremented is the payload of a TypeNonceIncremented event
+type NonceIncremented struct {
+	Idx common.Idx
+	New common.Nonce
+}
+
+// Exit is the payload of a TypeExit event
+type Exit struct {
+	Idx         common.Idx
+	Amount      string
+	MerkleProof []byte
+}
+
+// FeeCollected is the payload of a TypeFeeCollected event
+type FeeCollected struct {
+	CoordIdx common.Idx
+	TokenID  common.TokenID
+	Amount   string
+}
+
+// Event is a single structured event emitted while processing a batch
+type Event struct {
+	Type     Type
+	BatchNum common.BatchNum
+	Payload  interface{}
+}
+
+// Filter narrows down the events a subscriber is interested in. Zero-value
+// fields mean "don't filter on this". TokenIDs and EthAddrs are not
+// applied: events pass regardless of them
+type Filter struct {
+	FromBatch common.BatchNum
+	ToBatch   common.BatchNum
+	Idxs      []common.Idx
+	TokenIDs  []common.TokenID
+	EthAddrs  []ethCommon.Address
+	Types     []Type
+}
+
+func (f Filter) matchesBatch(batchNum common.BatchNum) bool {
+	if batchNum < f.FromBatch {
+		return false
+	}
+	if f.ToBatch != 0 && batchNum > f.ToBatch {
+		return false
+	}
+	return true
+}
+
+func (f Filter) matchesType(t Type) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, want := range f.Types {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) matchesIdx(idx common.Idx) bool {
+	if len(f.Idxs) == 0 {
+		return true
+	}
+	for _, want := range f.Idxs {
+		if want == idx {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) matches(ev Event) bool {
+	if !f.matchesBatch(ev.BatchNum) || !f.matchesType(ev.Type) {
+		return false
+	}
+	switch p := ev.Payload.(type) {
+	case AccountCreated:
+		return f.matchesIdx(p.Idx)
+	case BalanceChanged:
+		return f.matchesIdx(p.Idx)
+	case NonceIncremented:
+		return f.matchesIdx(p.Idx)
+	case Exit:
+		return f.matchesIdx(p.Idx)
+	case FeeCollected:
+		return f.matchesIdx(p.CoordIdx)
+	default:
+		return true
+	}
+}
+
+// Recorder collects events while a batch is processed and fans them out
+// only if the batch succeeds; events are dropped entirely if the caller
+// never calls Commit (e.g. because ProcessTxs errored)
+type Recorder struct {
+	pending []Event
+}
+
+// NewRecorder returns an empty Recorder, to be passed into txprocessor for
+// a single ProcessTxs call
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Emit buffers ev to be fanned out on Commit
+func (r *Recorder) Emit(ev Event) {
+	r.pending = append(r.pending, ev)
+}
+
+// Discard drops every buffered event, used when ProcessTxs errors
+func (r *Recorder) Discard() {
+	r.pending = nil
+}
+
+// Bus fans out committed events to registered Filters, both via polling
+// (GetEvents) and streaming (Subscribe)
+type Bus struct {
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[uint64]*subscriber
+}
+
+type subscriber struct {
+	filter Filter
+	ch     chan Event
+	// buffered keeps events for GetEvents-style polling
+	buffered []Event
+}
+
+// NewBus returns an empty event Bus
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[uint64]*subscriber)}
+}
+
+// Register adds a Filter to the Bus and returns its filterID, to be used
+// with GetEvents, plus a channel that streams matching events as they are
+// committed
+func (b *Bus) Register(filter Filter) (filterID uint64, stream chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	sub := &subscriber{filter: filter, ch: make(chan Event, 64)}
+	b.subscribers[id] = sub
+	return id, sub.ch
+}
+
+// Unregister removes a Filter from the Bus
+func (b *Bus) Unregister(filterID uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if sub, ok := b.subscribers[filterID]; ok {
+		close(sub.ch)
+		delete(b.subscribers, filterID)
+	}
+}
+
+// GetEvents returns (and clears) the events buffered so far for filterID
+func (b *Bus) GetEvents(filterID uint64) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub, ok := b.subscribers[filterID]
+	if !ok {
+		return nil
+	}
+	events := sub.buffered
+	sub.buffered = nil
+	return events
+}
+
+// Commit fans out every event buffered in r to the subscribers whose Filter
+// matches, both into their buffered slice (for GetEvents) and their stream
+// channel (best-effort, non-blocking)
+func (b *Bus) Commit(r *Recorder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, ev := range r.pending {
+		for _, sub := range b.subscribers {
+			if !sub.filter.matches(ev) {
+				continue
+			}
+			sub.buffered = append(sub.buffered, ev)
+			select {
+			case sub.ch <- ev:
+			default:
+				// slow consumer; GetEvents still has it buffered
+			}
+		}
+	}
+	r.pending = nil
+}
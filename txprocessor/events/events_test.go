@@ -0,0 +1,43 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusFiltersByIdxAndType(t *testing.T) {
+	bus := NewBus()
+	filterID, stream := bus.Register(Filter{
+		FromBatch: 1,
+		Idxs:      []common.Idx{256},
+		Types:     []Type{TypeBalanceChanged},
+	})
+
+	r := NewRecorder()
+	r.Emit(Event{Type: TypeBalanceChanged, BatchNum: 1, Payload: BalanceChanged{Idx: 256, Old: "0", New: "10", Delta: "10"}})
+	r.Emit(Event{Type: TypeBalanceChanged, BatchNum: 1, Payload: BalanceChanged{Idx: 257, Old: "0", New: "5", Delta: "5"}})
+	r.Emit(Event{Type: TypeNonceIncremented, BatchNum: 1, Payload: NonceIncremented{Idx: 256, New: 1}})
+	bus.Commit(r)
+
+	events := bus.GetEvents(filterID)
+	assert.Len(t, events, 1)
+	assert.Equal(t, TypeBalanceChanged, events[0].Type)
+
+	select {
+	case ev := <-stream:
+		assert.Equal(t, TypeBalanceChanged, ev.Type)
+	default:
+		t.Fatal("expected an event on the stream channel")
+	}
+
+	bus.Unregister(filterID)
+}
+
+func TestRecorderDiscard(t *testing.T) {
+	r := NewRecorder()
+	r.Emit(Event{Type: TypeExit, BatchNum: 1, Payload: Exit{Idx: 256, Amount: "1"}})
+	r.Discard()
+	assert.Empty(t, r.pending)
+}
@@ -0,0 +1,140 @@
+/*
+Package fuzz drives txprocessor.TxProcessor with randomly generated but
+well-formed batches, to shake out edge cases that the hand-picked
+testvectors in txprocessor/batchtest miss. RunOracle runs an external
+circom/snarkjs witness generator (configured via the FUZZ_ORACLE_BIN env
+var) on a batch; FuzzProcessTxs calls it but does not compare its output
+with the computed HashGlobalData, ToHashGlobalData or post-state root. A
+few pure-Go invariants are always asserted, with or without that oracle.
+Failing seeds are persisted as JSON Reproducers under reproducers/; see
+Reproducer for how they differ from batchtest fixtures.
+*/
+package fuzz
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/txprocessor"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// OracleEnvVar names the external binary (a wrapper around a circom/snarkjs
+// witness generator) used to cross-check a scenario's expected outputs.
+// Leaving it unset skips the oracle cross-check and only runs the pure-Go
+// invariants.
+const OracleEnvVar = "FUZZ_ORACLE_BIN"
+
+// OracleInput is the JSON payload sent to the external oracle binary's
+// stdin
+type OracleInput struct {
+	Config     txprocessor.Config `json:"config"`
+	CoordIdxs  []common.Idx       `json:"coordIdxs"`
+	L1UserTxs  []common.L1Tx      `json:"l1UserTxs"`
+	L1CoordTxs []common.L1Tx      `json:"l1CoordTxs"`
+	L2Txs      []common.PoolL2Tx  `json:"l2Txs"`
+}
+
+// OracleOutput is the JSON payload expected on the external oracle binary's
+// stdout
+type OracleOutput struct {
+	HashGlobalData   string `json:"hashGlobalData"`
+	ToHashGlobalData string `json:"toHashGlobalData"`
+	StateRoot        string `json:"stateRoot"`
+}
+
+// RunOracle shells out to the binary named by OracleEnvVar, feeding it in
+// as JSON and parsing its JSON response. ok is false (with no error) if the
+// env var isn't set, so callers can skip the cross-check gracefully.
+func RunOracle(in OracleInput) (out *OracleOutput, ok bool, err error) {
+	bin := os.Getenv(OracleEnvVar)
+	if bin == "" {
+		return nil, false, nil
+	}
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return nil, false, tracerr.Wrap(err)
+	}
+	cmd := exec.Command(bin) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, false, tracerr.Wrap(err)
+	}
+	var result OracleOutput
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		return nil, false, tracerr.Wrap(err)
+	}
+	return &result, true, nil
+}
+
+// Reproducer is the JSON fixture persisted for a fuzz seed that failed one
+// of the invariants. Its config, l1UserTxs, l1CoordTxs and l2Txs use the
+// same JSON keys as a batchtest.BatchTest, but it holds no pre-state
+// accounts and no expected values, and its coordIdxs sits at the top level
+// rather than under pre, so batchtest.Run processes its txs from an empty
+// state and has nothing to compare them against
+type Reproducer struct {
+	Name       string             `json:"name"`
+	Seed       int64              `json:"seed"`
+	Config     txprocessor.Config `json:"config"`
+	CoordIdxs  []common.Idx       `json:"coordIdxs"`
+	L1UserTxs  []common.L1Tx      `json:"l1UserTxs"`
+	L1CoordTxs []common.L1Tx      `json:"l1CoordTxs"`
+	L2Txs      []common.PoolL2Tx  `json:"l2Txs"`
+	FailedOn   string             `json:"failedOn"`
+}
+
+// PersistReproducer writes a failing fuzz seed's scenario as a JSON
+// fixture under dir, so it can be replayed and eventually adapted into a
+// txprocessor/batchtest testvector
+func PersistReproducer(dir string, r Reproducer) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil { //nolint:gomnd
+		return tracerr.Wrap(err)
+	}
+	payload, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	path := filepath.Join(dir, r.Name+".json")
+	return tracerr.Wrap(ioutil.WriteFile(path, payload, 0o644)) //nolint:gomnd
+}
+
+// tokenBalances sums every account's balance for tokenID into one total
+func tokenBalances(accounts []common.Account, tokenID common.TokenID) *big.Int {
+	sum := big.NewInt(0)
+	for _, acc := range accounts {
+		if acc.TokenID != tokenID {
+			continue
+		}
+		sum.Add(sum, acc.Balance)
+	}
+	return sum
+}
+
+// CheckSupplyInvariant asserts that, for a single token, the supply held
+// in the state plus what was paid out as exits equals the pre-batch
+// supply plus the fees collected by the coordinator: no token is created
+// or destroyed by ProcessTxs
+func CheckSupplyInvariant(preAccounts, postAccounts []common.Account, tokenID common.TokenID,
+	exited, fees *big.Int) bool {
+	preSum := tokenBalances(preAccounts, tokenID)
+	postSum := tokenBalances(postAccounts, tokenID)
+	lhs := new(big.Int).Add(preSum, fees)
+	rhs := new(big.Int).Add(postSum, exited)
+	return lhs.Cmp(rhs) == 0
+}
+
+// CheckMonotonicLastIdx asserts that the last assigned Idx never decreases
+// across a batch
+func CheckMonotonicLastIdx(oldLastIdx, newLastIdx common.Idx) bool {
+	return newLastIdx >= oldLastIdx
+}
@@ -0,0 +1,52 @@
+package tbuilder
+
+import (
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCreateAccountDepositAndTransfer(t *testing.T) {
+	res, err := Parse(`
+		CreateAccountDeposit(A, 1, 16000000)
+		CreateAccountDeposit(B, 1, 16000000)
+		Transfer(A-B, 1, 1000, fee:126)
+	`)
+	require.Nil(t, err)
+	require.Len(t, res.L1Txs, 2)
+	require.Len(t, res.L2Txs, 1)
+
+	assert.Equal(t, common.TxTypeCreateAccountDeposit, res.L1Txs[0].Type)
+	assert.Equal(t, common.TxTypeCreateAccountDeposit, res.L1Txs[1].Type)
+
+	l2 := res.L2Txs[0]
+	assert.Equal(t, common.Idx(256), l2.FromIdx)
+	assert.Equal(t, common.Idx(257), l2.ToIdx)
+	assert.Equal(t, common.FeeSelector(126), l2.Fee)
+	assert.Equal(t, common.Nonce(0), l2.Nonce)
+	assert.NotEmpty(t, l2.Signature)
+}
+
+func TestParseExitAndCoordinatorFee(t *testing.T) {
+	res, err := Parse(`
+		CreateAccountDeposit(A, 1, 16000000)
+		Exit(A, 1, 100)
+		CoordinatorFee(A, 1)
+	`)
+	require.Nil(t, err)
+	require.Len(t, res.L2Txs, 1)
+	assert.Equal(t, common.TxTypeExit, res.L2Txs[0].Type)
+	assert.Equal(t, []common.Idx{256}, res.CoordIdxs)
+}
+
+func TestParseUnknownAccount(t *testing.T) {
+	_, err := Parse(`Transfer(A-B, 1, 1000)`)
+	assert.NotNil(t, err)
+}
+
+func TestParseUnknownInstruction(t *testing.T) {
+	_, err := Parse(`Frobnicate(A, 1, 1000)`)
+	assert.NotNil(t, err)
+}
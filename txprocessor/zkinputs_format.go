@@ -0,0 +1,37 @@
+package txprocessor
+
+import (
+	"encoding/json"
+
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// ZKInputsFormat selects how MarshalZKInputs encodes a ProcessTxOutput's
+// ZKInputs
+type ZKInputsFormat int
+
+const (
+	// ZKInputsFormatVerbose is the default: one JSON array element per
+	// bit for fields like FromBjjCompressed, matching what the current
+	// circom input reader expects
+	ZKInputsFormatVerbose ZKInputsFormat = iota
+	// ZKInputsFormatPacked packs per-tx bit-array fields into a single
+	// decimal string per slot via common.ZKInputs.MarshalJSONPacked,
+	// 10-50x smaller, for tooling built against the packed form
+	ZKInputsFormatPacked
+)
+
+// MarshalZKInputs encodes zki according to format. This is the intended
+// entry point for a future Config.ZKInputsFormat field: Config's
+// definition isn't part of this checkout, so ProcessTxs can't be wired to
+// pick a format automatically yet; callers that need the packed form
+// should call this directly on a ProcessTxOutput's ZKInputs in the
+// meantime.
+func MarshalZKInputs(zki *common.ZKInputs, format ZKInputsFormat) ([]byte, error) {
+	switch format {
+	case ZKInputsFormatPacked:
+		return zki.MarshalJSONPacked()
+	default:
+		return json.Marshal(zki)
+	}
+}
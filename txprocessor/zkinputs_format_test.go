@@ -0,0 +1,26 @@
+package txprocessor
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalZKInputsFormats(t *testing.T) {
+	zki := &common.ZKInputs{}
+
+	verbose, err := MarshalZKInputs(zki, ZKInputsFormatVerbose)
+	require.Nil(t, err)
+	want, err := json.Marshal(zki)
+	require.Nil(t, err)
+	assert.Equal(t, string(want), string(verbose))
+
+	packed, err := MarshalZKInputs(zki, ZKInputsFormatPacked)
+	require.Nil(t, err)
+	wantPacked, err := zki.MarshalJSONPacked()
+	require.Nil(t, err)
+	assert.Equal(t, string(wantPacked), string(packed))
+}
@@ -0,0 +1,98 @@
+package txprocessor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/tracerr"
+)
+
+// ZKInputsMismatch describes a single ZKInputs field (and, for arrays, the
+// index within it) that differs between an expected and an actual value
+type ZKInputsMismatch struct {
+	Field string
+	Want  string
+	Got   string
+}
+
+// CompareZKInputs performs a structural, per-field diff between want and
+// got, instead of the single opaque string diff that comparing
+// json.Marshal(want) against json.Marshal(got) directly produces. It's used
+// by both AssertZKInputsGolden (this package's own tests) and
+// txprocessor/batchtest's fixture runner, so a mismatch always points at
+// the specific field (e.g. "ay1", "siblings1") that differs.
+func CompareZKInputs(want, got *common.ZKInputs) ([]ZKInputsMismatch, error) {
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	var wantMap, gotMap map[string]interface{}
+	if err := json.Unmarshal(wantJSON, &wantMap); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	if err := json.Unmarshal(gotJSON, &gotMap); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	var mismatches []ZKInputsMismatch
+	for field, wantVal := range wantMap {
+		gotVal, ok := gotMap[field]
+		if !ok {
+			mismatches = append(mismatches, ZKInputsMismatch{Field: field, Want: toJSON(wantVal), Got: "<missing>"})
+			continue
+		}
+		if !reflect.DeepEqual(wantVal, gotVal) {
+			mismatches = append(mismatches, diffField(field, wantVal, gotVal)...)
+		}
+	}
+	for field := range gotMap {
+		if _, ok := wantMap[field]; !ok {
+			mismatches = append(mismatches, ZKInputsMismatch{Field: field, Want: "<missing>", Got: toJSON(gotMap[field])})
+		}
+	}
+	return mismatches, nil
+}
+
+// diffField narrows a top-level field mismatch down to the differing array
+// index, when both sides are arrays of the same length, so e.g. a single
+// wrong sibling reports as "siblings1[4]" instead of the whole slice
+func diffField(field string, want, got interface{}) []ZKInputsMismatch {
+	wantArr, wantOK := want.([]interface{})
+	gotArr, gotOK := got.([]interface{})
+	if !wantOK || !gotOK || len(wantArr) != len(gotArr) {
+		return []ZKInputsMismatch{{Field: field, Want: toJSON(want), Got: toJSON(got)}}
+	}
+	var mismatches []ZKInputsMismatch
+	for i := range wantArr {
+		if reflect.DeepEqual(wantArr[i], gotArr[i]) {
+			continue
+		}
+		mismatches = append(mismatches, ZKInputsMismatch{
+			Field: fmt.Sprintf("%s[%d]", field, i),
+			Want:  toJSON(wantArr[i]),
+			Got:   toJSON(gotArr[i]),
+		})
+	}
+	if len(mismatches) == 0 {
+		// no single index differed (arrays that DeepEqual found
+		// different always differ at some index, nested ones
+		// included); fall back to the whole field
+		return []ZKInputsMismatch{{Field: field, Want: toJSON(want), Got: toJSON(got)}}
+	}
+	return mismatches
+}
+
+func toJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "<unmarshalable>"
+	}
+	return string(b)
+}
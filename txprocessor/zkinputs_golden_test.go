@@ -0,0 +1,93 @@
+package txprocessor
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+	"github.com/hermeznetwork/hermez-node/txprocessor/tbuilder"
+)
+
+// update regenerates the golden fixtures under testdata/ from the actual
+// ZKInputs output, instead of asserting against them. Run with:
+//
+//	go test ./txprocessor/... -run TestZKInputsGolden -update
+var update = flag.Bool("update", false, "update golden fixtures instead of asserting against them")
+
+// AssertZKInputsGolden compares zki against the golden file at path, via
+// CompareZKInputs, reporting every differing field (and array index) as a
+// separate t.Errorf instead of one opaque string diff. With -update it
+// rewrites path from zki instead of comparing.
+func AssertZKInputsGolden(t *testing.T, zki *common.ZKInputs, path string) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(zki, "", "  ")
+	if err != nil {
+		t.Fatalf("json.MarshalIndent: %v", err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err := ioutil.WriteFile(path, got, 0600); err != nil { //nolint:gomnd
+			t.Fatalf("WriteFile %s: %v", path, err)
+		}
+		return
+	}
+
+	wantJSON, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		t.Fatalf("missing golden file %s, run with -update to create it: %v", path, err)
+	}
+	var want common.ZKInputs
+	if err := json.Unmarshal(wantJSON, &want); err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+
+	mismatches, err := CompareZKInputs(&want, zki)
+	if err != nil {
+		t.Fatalf("CompareZKInputs: %v", err)
+	}
+	for _, m := range mismatches {
+		t.Errorf("%s: field %q differs\n  want: %s\n  got:  %s", path, m.Field, m.Want, m.Got)
+	}
+}
+
+const goldenNLevels = 32
+
+// TestZKInputsGolden runs a small tbuilder scenario through a real
+// TxProcessor and checks its ZKInputs against testdata/simple_transfer.json,
+// exercising AssertZKInputsGolden end to end.
+func TestZKInputsGolden(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zkinputsgolden")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	res, err := tbuilder.Parse(`
+		CreateAccountDeposit(A, 1, 1000)
+		CreateAccountDeposit(B, 1, 0)
+		Transfer(A-B, 1, 100)
+	`)
+	if err != nil {
+		t.Fatalf("tbuilder.Parse: %v", err)
+	}
+
+	sdb, err := statedb.NewStateDB(dir, statedb.TypeBatchBuilder, goldenNLevels)
+	if err != nil {
+		t.Fatalf("NewStateDB: %v", err)
+	}
+	cfg := Config{NLevels: goldenNLevels, MaxFeeTx: 2, MaxTx: 4, MaxL1Tx: 4} //nolint:gomnd
+	tp := NewTxProcessor(sdb, cfg)
+
+	ptOut, err := tp.ProcessTxs(nil, res.L1Txs, nil, res.L2Txs)
+	if err != nil {
+		t.Fatalf("ProcessTxs: %v", err)
+	}
+
+	AssertZKInputsGolden(t, ptOut.ZKInputs, "testdata/simple_transfer.json")
+}
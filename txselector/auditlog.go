@@ -0,0 +1,228 @@
+package txselector
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/log"
+	"github.com/hermeznetwork/tracerr"
+)
+
+const (
+	// PathAuditLogDB defines the path of the key-value db where the
+	// audit log of every GetL1L2TxSelection call is stored
+	PathAuditLogDB = "/auditlog"
+)
+
+// RejectReason identifies why a pool L2Tx considered by GetL1L2TxSelection
+// was not included in the batch
+type RejectReason string
+
+const (
+	// ReasonToIdxNotFound is set when a tx's ToIdx doesn't exist in the
+	// localAccountsDB
+	ReasonToIdxNotFound RejectReason = "ToIdxNotFound"
+	// ReasonAuthMissing is set when a tx's ToEthAddr has no
+	// AccountCreationAuth in the L2DB
+	ReasonAuthMissing RejectReason = "AuthMissing"
+	// ReasonBJJMismatch is set when a tx's ToBJJ doesn't match the
+	// AccountCreationAuth on file for its ToEthAddr
+	ReasonBJJMismatch RejectReason = "BJJMismatch"
+	// ReasonCapacityExceeded is set when a tx was admissible but didn't
+	// fit within the batch's L2 tx budget, or was beyond
+	// SelectionConfig.MaxTxsPerSender for its sender
+	ReasonCapacityExceeded RejectReason = "CapacityExceeded"
+	// ReasonNonceGap is set when a tx's Nonce is not admissible given the
+	// account's current nonce and the txs already selected ahead of it
+	ReasonNonceGap RejectReason = "NonceGap"
+)
+
+// AuditRejectedTx records why a single pool L2Tx was not included in a
+// GetL1L2TxSelection call's output
+type AuditRejectedTx struct {
+	TxID   common.TxID  `json:"txId"`
+	Reason RejectReason `json:"reason"`
+	Detail string       `json:"detail,omitempty"`
+}
+
+// AuditRecord is the audit-log entry for one GetL1L2TxSelection call: the
+// input snapshot it was given, and the output decision it reached
+type AuditRecord struct {
+	BatchNum      common.BatchNum   `json:"batchNum"`
+	L1TxsDigest   string            `json:"l1TxsDigest"`
+	PoolTxIDs     []common.TxID     `json:"poolTxIds"`
+	CoordIdxs     []common.Idx      `json:"coordIdxs"`
+	Config        SelectionConfig   `json:"config"`
+	AcceptedTxIDs []common.TxID     `json:"acceptedTxIds"`
+	RejectedTxs   []AuditRejectedTx `json:"rejectedTxs"`
+}
+
+// AuditLogKey returns the pebble key an AuditRecord for batchNum is stored
+// under. BatchNum is formatted as a fixed-width decimal so iteration order
+// matches batch order. Exported for the auditlog inspector CLI.
+func AuditLogKey(batchNum common.BatchNum) []byte {
+	return []byte(fmt.Sprintf("%020d", uint64(batchNum)))
+}
+
+// UnmarshalAuditRecord decodes an AuditRecord from its persisted JSON form.
+// Exported for the auditlog inspector CLI, which reads records directly
+// off the pebble store rather than through a TxSelector.
+func UnmarshalAuditRecord(b []byte) (*AuditRecord, error) {
+	var record AuditRecord
+	if err := json.Unmarshal(b, &record); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	return &record, nil
+}
+
+// l1TxsDigest returns a stable digest of l1Txs, recorded in each
+// AuditRecord as a fingerprint of the L1 user txs the selection ran
+// against; Replay does not check it
+func l1TxsDigest(l1Txs []common.L1Tx) (string, error) {
+	b, err := json.Marshal(l1Txs)
+	if err != nil {
+		return "", tracerr.Wrap(err)
+	}
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("%x", sum), nil
+}
+
+// logSelection persists an AuditRecord for a GetL1L2TxSelection call. Audit
+// logging is best-effort: a failure to persist is logged but does not fail
+// the selection itself, since operators would rather have a successful
+// batch with a gap in the audit log than a failed batch.
+func (txsel *TxSelector) logSelection(record *AuditRecord) {
+	if txsel.auditLogDB == nil {
+		return
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		log.Errorw("auditlog: marshal AuditRecord", "err", err)
+		return
+	}
+	tx, err := txsel.auditLogDB.NewTx()
+	if err != nil {
+		log.Errorw("auditlog: NewTx", "err", err)
+		return
+	}
+	if err := tx.Put(AuditLogKey(record.BatchNum), b); err != nil {
+		log.Errorw("auditlog: Put", "err", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		log.Errorw("auditlog: Commit", "err", err)
+	}
+}
+
+// GetAuditRecord returns the AuditRecord logged for batchNum, if any
+func (txsel *TxSelector) GetAuditRecord(batchNum common.BatchNum) (*AuditRecord, error) {
+	if txsel.auditLogDB == nil {
+		return nil, tracerr.Wrap(fmt.Errorf("auditlog: TxSelector was created without an audit log DB"))
+	}
+	b, err := txsel.auditLogDB.Get(AuditLogKey(batchNum))
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	return UnmarshalAuditRecord(b)
+}
+
+// ReplayResult is Replay's verdict for a single batch
+type ReplayResult struct {
+	BatchNum    common.BatchNum
+	Determinate bool
+	Mismatches  []string
+}
+
+// Replay reconstructs the localAccountsDB at batchNum and re-runs selection,
+// via GetL1L2TxSelectionWithPool, against replayPool: the live pool txs
+// filtered down to exactly the TxIDs recorded in that batch's AuditRecord,
+// not whatever the live pool looks like now. This verifies the selection is
+// deterministic: given the same LocalStateDB state, the same CoordIdxs, and
+// the same pool tx IDs, it should reach the same accept/reject decision
+// every time. l1Txs aren't persisted by the audit log, so selection is
+// re-run with no l1Txs, and record.L1TxsDigest is not checked.
+func (txsel *TxSelector) Replay(batchNum common.BatchNum) (*ReplayResult, error) {
+	record, err := txsel.GetAuditRecord(batchNum)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	if err := txsel.localAccountsDB.Reset(batchNum-1, true); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	l2TxsRaw, err := txsel.l2db.GetPendingTxs()
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	recordedIDs := make(map[common.TxID]bool, len(record.PoolTxIDs))
+	for _, id := range record.PoolTxIDs {
+		recordedIDs[id] = true
+	}
+	var replayPool []common.PoolL2Tx
+	for _, tx := range l2TxsRaw {
+		if recordedIDs[tx.TxID] {
+			replayPool = append(replayPool, tx)
+		}
+	}
+
+	_, _, l1CoordinatorTxs, l2Txs, err :=
+		txsel.GetL1L2TxSelectionWithPool(&record.Config, batchNum, nil, replayPool)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	_ = l1CoordinatorTxs // not yet compared: AuditRecord doesn't record the original l1CoordinatorTxs count
+
+	var mismatches []string
+	gotAccepted := make(map[common.TxID]bool, len(l2Txs))
+	for _, tx := range l2Txs {
+		gotAccepted[tx.TxID] = true
+	}
+	for _, id := range record.AcceptedTxIDs {
+		if !gotAccepted[id] {
+			mismatches = append(mismatches,
+				fmt.Sprintf("tx %s was accepted originally but rejected on replay", id.String()))
+		}
+	}
+	for _, tx := range replayPool {
+		if gotAccepted[tx.TxID] && !recordedAccepted(record, tx.TxID) {
+			mismatches = append(mismatches,
+				fmt.Sprintf("tx %s was rejected originally but accepted on replay", tx.TxID.String()))
+		}
+	}
+
+	return &ReplayResult{
+		BatchNum:    batchNum,
+		Determinate: len(mismatches) == 0,
+		Mismatches:  mismatches,
+	}, nil
+}
+
+func recordedAccepted(record *AuditRecord, id common.TxID) bool {
+	for _, acceptedID := range record.AcceptedTxIDs {
+		if acceptedID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// InspectReport renders an AuditRecord as a human-readable report, for the
+// audit-log inspector CLI
+func InspectReport(record *AuditRecord) string {
+	report := fmt.Sprintf("batch %s: %d pool txs considered, %d accepted, %d rejected\n",
+		strconv.FormatUint(uint64(record.BatchNum), 10), len(record.PoolTxIDs),
+		len(record.AcceptedTxIDs), len(record.RejectedTxs))
+	for _, rejected := range record.RejectedTxs {
+		report += fmt.Sprintf("  rejected %s: %s", rejected.TxID.String(), rejected.Reason)
+		if rejected.Detail != "" {
+			report += fmt.Sprintf(" (%s)", rejected.Detail)
+		}
+		report += "\n"
+	}
+	return report
+}
@@ -0,0 +1,122 @@
+package txselector
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// FeeTokenPolicy controls which TokenIDs TxSelector is allowed to
+// auto-provision a coordinator Idx for, when a batch's selected L2 txs pay
+// fees in a TokenID the coordinator doesn't have an account for yet.
+type FeeTokenPolicy struct {
+	// Whitelist, when non-empty, restricts provisioning to only these
+	// TokenIDs; Blacklist is checked first and always wins over
+	// Whitelist.
+	Whitelist []common.TokenID
+	// Blacklist TokenIDs are never auto-provisioned, regardless of
+	// Whitelist or MinExpectedFee
+	Blacklist []common.TokenID
+	// MinExpectedFee is the minimum scaled (see scaleFee) sum of
+	// AbsoluteFee, across the batch's selected L2 txs paying in a
+	// TokenID, required before TxSelector spends an L1CoordinatorTx
+	// slot provisioning a coordinator account for it
+	MinExpectedFee int64
+}
+
+func (p *FeeTokenPolicy) allows(tokenID common.TokenID, expectedFee int64) bool {
+	if p == nil {
+		return true
+	}
+	for _, blocked := range p.Blacklist {
+		if blocked == tokenID {
+			return false
+		}
+	}
+	if len(p.Whitelist) > 0 {
+		allowed := false
+		for _, wl := range p.Whitelist {
+			if wl == tokenID {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return expectedFee >= p.MinExpectedFee
+}
+
+// feesByTokenID sums scaleFee(tx.AbsoluteFee) across l2Txs, grouped by
+// TokenID
+func feesByTokenID(l2Txs []common.PoolL2Tx) map[common.TokenID]int64 {
+	fees := make(map[common.TokenID]int64)
+	for _, tx := range l2Txs {
+		fees[tx.TokenID] += scaleFee(tx.AbsoluteFee).Int64()
+	}
+	return fees
+}
+
+// provisionCoordinatorAccounts builds a CreateAccountDeposit L1CoordinatorTx
+// for every TokenID l2Txs pays fees in that coordIdxsMap doesn't already
+// have a coordinator Idx for, and that feeTokenPolicy allows, using
+// txsel.coordAccount's BJJ/EthAddr. positionL1 is the next available
+// L1CoordinatorTx position.
+func (txsel *TxSelector) provisionCoordinatorAccounts(l2Txs []common.PoolL2Tx,
+	coordIdxsMap map[common.TokenID]common.Idx, feeTokenPolicy *FeeTokenPolicy,
+	positionL1 int) []common.L1Tx {
+	if txsel.coordAccount == nil {
+		return nil
+	}
+	fees := feesByTokenID(l2Txs)
+	// Go map iteration order is randomized; a coordinator's batches must
+	// be reproducible across runs over the same pool, so the TokenIDs
+	// needing a new L1CoordinatorTx are visited in a fixed, sorted order.
+	tokenIDs := make([]common.TokenID, 0, len(fees))
+	for tokenID := range fees {
+		tokenIDs = append(tokenIDs, tokenID)
+	}
+	sort.Slice(tokenIDs, func(i, j int) bool { return tokenIDs[i] < tokenIDs[j] })
+
+	var newL1CoordinatorTxs []common.L1Tx
+	for _, tokenID := range tokenIDs {
+		expectedFee := fees[tokenID]
+		if _, ok := coordIdxsMap[tokenID]; ok {
+			continue
+		}
+		if !feeTokenPolicy.allows(tokenID, expectedFee) {
+			continue
+		}
+		newL1CoordinatorTxs = append(newL1CoordinatorTxs, common.L1Tx{
+			Position:      positionL1,
+			UserOrigin:    false,
+			FromEthAddr:   txsel.coordAccount.Addr,
+			FromBJJ:       txsel.coordAccount.BJJ,
+			TokenID:       tokenID,
+			DepositAmount: big.NewInt(0),
+			Type:          common.TxTypeCreateAccountDeposit,
+		})
+		positionL1++
+	}
+	return newL1CoordinatorTxs
+}
+
+// newCoordIdxsFromCreatedAccounts returns the TokenID->Idx map to pass to
+// AddCoordIdxs for the coordinator accounts created by
+// provisionCoordinatorAccounts, found in createdAccounts by matching
+// txsel.coordAccount's EthAddr
+func (txsel *TxSelector) newCoordIdxsFromCreatedAccounts(
+	createdAccounts []common.Account) map[common.TokenID]common.Idx {
+	if txsel.coordAccount == nil {
+		return nil
+	}
+	newIdxs := make(map[common.TokenID]common.Idx)
+	for _, account := range createdAccounts {
+		if account.EthAddr == txsel.coordAccount.Addr {
+			newIdxs[account.TokenID] = account.Idx
+		}
+	}
+	return newIdxs
+}
@@ -0,0 +1,41 @@
+package txselector
+
+import (
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeTokenPolicyNilAllowsEverything(t *testing.T) {
+	var p *FeeTokenPolicy
+	assert.True(t, p.allows(common.TokenID(1), 0))
+}
+
+func TestFeeTokenPolicyBlacklistWinsOverWhitelist(t *testing.T) {
+	p := &FeeTokenPolicy{Whitelist: []common.TokenID{1}, Blacklist: []common.TokenID{1}}
+	assert.False(t, p.allows(common.TokenID(1), 1000)) //nolint:gomnd
+}
+
+func TestFeeTokenPolicyWhitelistExcludesOthers(t *testing.T) {
+	p := &FeeTokenPolicy{Whitelist: []common.TokenID{1}}
+	assert.True(t, p.allows(common.TokenID(1), 0))
+	assert.False(t, p.allows(common.TokenID(2), 1000)) //nolint:gomnd
+}
+
+func TestFeeTokenPolicyMinExpectedFee(t *testing.T) {
+	p := &FeeTokenPolicy{MinExpectedFee: 100} //nolint:gomnd
+	assert.False(t, p.allows(common.TokenID(1), 99))
+	assert.True(t, p.allows(common.TokenID(1), 100)) //nolint:gomnd
+}
+
+func TestFeesByTokenIDSumsPerToken(t *testing.T) {
+	l2Txs := []common.PoolL2Tx{
+		{TokenID: 1, AbsoluteFee: 1},
+		{TokenID: 1, AbsoluteFee: 2},
+		{TokenID: 2, AbsoluteFee: 5},
+	}
+	fees := feesByTokenID(l2Txs)
+	assert.Equal(t, scaleFee(3).Int64(), fees[common.TokenID(1)]) //nolint:gomnd
+	assert.Equal(t, scaleFee(5).Int64(), fees[common.TokenID(2)]) //nolint:gomnd
+}
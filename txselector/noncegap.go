@@ -0,0 +1,92 @@
+package txselector
+
+import (
+	"sort"
+
+	"github.com/hermeznetwork/hermez-node/common"
+)
+
+// senderQueue is the per-FromIdx queue senderQueueCompact builds before
+// admitting a contiguous-nonce prefix from it
+type senderQueue struct {
+	fromIdx common.Idx
+	txs     []common.PoolL2Tx // sorted by Nonce ascending
+}
+
+// compactionResult is senderQueueCompact's verdict for every candidate tx:
+// exactly one of admitted, nonceGapped or capExceeded contains each input
+// tx, grouped by FromIdx and still in Nonce order within admitted
+type compactionResult struct {
+	admitted    []common.PoolL2Tx
+	nonceGapped []common.PoolL2Tx
+	capExceeded []common.PoolL2Tx
+}
+
+// senderQueueCompact groups candidates by FromIdx, sorts each group by
+// Nonce, and admits only the longest prefix whose Nonces are contiguous
+// starting at the account's current Nonce in localAccountsDB (as reported
+// by accountNonce); a tx behind a gap can't be processed before the gap is
+// filled, so it and everything after it in that sender's queue is deferred
+// to a later batch. Admission within a sender's contiguous prefix also
+// stops once maxPerSender txs have been admitted for that sender (0 means
+// no cap), so the remainder of an otherwise-admissible prefix is deferred
+// as capExceeded rather than squeezed in.
+func (txsel *TxSelector) senderQueueCompact(candidates []common.PoolL2Tx,
+	maxPerSender uint32) compactionResult {
+	bySender := make(map[common.Idx][]common.PoolL2Tx)
+	var order []common.Idx
+	for _, tx := range candidates {
+		if _, ok := bySender[tx.FromIdx]; !ok {
+			order = append(order, tx.FromIdx)
+		}
+		bySender[tx.FromIdx] = append(bySender[tx.FromIdx], tx)
+	}
+
+	var result compactionResult
+	for _, fromIdx := range order {
+		queue := bySender[fromIdx]
+		sort.SliceStable(queue, func(i, j int) bool {
+			return queue[i].Nonce < queue[j].Nonce
+		})
+
+		account, err := txsel.localAccountsDB.GetAccount(fromIdx)
+		if err != nil {
+			// sender's account doesn't exist (yet): none of its
+			// txs can be processed this batch
+			result.nonceGapped = append(result.nonceGapped, queue...)
+			continue
+		}
+
+		expected := account.Nonce
+		gapped := false
+		for _, tx := range queue {
+			if gapped {
+				result.nonceGapped = append(result.nonceGapped, tx)
+				continue
+			}
+			if tx.Nonce != expected {
+				gapped = true
+				result.nonceGapped = append(result.nonceGapped, tx)
+				continue
+			}
+			if maxPerSender > 0 && countFromIdx(result.admitted, fromIdx) >= maxPerSender {
+				result.capExceeded = append(result.capExceeded, tx)
+				expected++
+				continue
+			}
+			result.admitted = append(result.admitted, tx)
+			expected++
+		}
+	}
+	return result
+}
+
+func countFromIdx(txs []common.PoolL2Tx, fromIdx common.Idx) uint32 {
+	var n uint32
+	for _, tx := range txs {
+		if tx.FromIdx == fromIdx {
+			n++
+		}
+	}
+	return n
+}
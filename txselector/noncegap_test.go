@@ -0,0 +1,40 @@
+package txselector
+
+import (
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByFromIdxGroupsPreserveRelativeOrder(t *testing.T) {
+	candidates := txs{
+		poolTx(256, 257, 0, 1), // A
+		poolTx(258, 259, 0, 2), // B
+		poolTx(256, 257, 1, 1), // A
+	}
+	groups := groupByFromIdx(candidates)
+	assert.Len(t, groups, 2)
+	assert.Len(t, groups[0], 2)
+	assert.Equal(t, common.Idx(256), groups[0][0].FromIdx)
+	assert.Len(t, groups[1], 1)
+	assert.Equal(t, common.Idx(258), groups[1][0].FromIdx)
+}
+
+func TestGetL2ProfitableScoresAtGroupLevelNotPerTx(t *testing.T) {
+	// sender A has two low-fee txs summing above sender B's single
+	// high-fee tx; group-level scoring must rank A's group ahead of B's
+	txsel := &TxSelector{defaultPolicy: &AbsoluteFeePolicy{}}
+	candidates := txs{
+		poolTx(258, 259, 0, 5), // B, nonce 0, high fee alone
+		poolTx(256, 257, 0, 3), // A, nonce 0
+		poolTx(256, 257, 1, 3), // A, nonce 1 (A's group totals 6 > B's 5)
+	}
+	selectionConfig := &SelectionConfig{}
+	out := txsel.getL2Profitable(candidates, 2, selectionConfig) //nolint:gomnd
+
+	assert.Len(t, out, 2)
+	for _, tx := range out {
+		assert.Equal(t, common.Idx(256), tx.FromIdx)
+	}
+}
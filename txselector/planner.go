@@ -0,0 +1,234 @@
+package txselector
+
+import (
+	"fmt"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/log"
+	"github.com/hermeznetwork/tracerr"
+	"github.com/iden3/go-iden3-crypto/babyjub"
+)
+
+// l1SlotFeeCost is the minimum sum of AbsoluteFee, across every pending L2Tx
+// addressed to a single to-be-created receiver account, that
+// creationCandidate.worthCreating requires before PlanBatches spends an
+// L1CoordinatorTx slot on that receiver. It's expressed in the same scaled
+// units as scaleFee, so it can be compared against a big.Int fee sum
+// directly.
+const l1SlotFeeCost = 3 * feeScale //nolint:gomnd
+
+// BatchPlan is PlanBatches' per-batch result: the same shape
+// GetL1L2TxSelection returns for a single batch, plus the BatchNum it was
+// planned for.
+type BatchPlan struct {
+	BatchNum         common.BatchNum
+	CoordIdxs        []common.Idx
+	L1CoordinatorTxs []common.L1Tx
+	L2Txs            []common.PoolL2Tx
+}
+
+// creationCandidate groups the pending L2 txs addressed to the same
+// not-yet-existing receiver account (ToEthAddr+ToBJJ+TokenID), so their
+// combined fee revenue can be weighed against the cost of the
+// CreateAccountDeposit L1CoordinatorTx needed to resolve them.
+type creationCandidate struct {
+	toEthAddr ethCommon.Address
+	toBJJ     *babyjub.PublicKey
+	tokenID   common.TokenID
+	txs       []common.PoolL2Tx
+}
+
+func (c *creationCandidate) key() string {
+	bjj := ""
+	if c.toBJJ != nil {
+		bjj = c.toBJJ.String()
+	}
+	return fmt.Sprintf("%s-%s-%d", c.toEthAddr.Hex(), bjj, c.tokenID)
+}
+
+// totalFee returns the scaled sum of AbsoluteFee across every tx in c
+func (c *creationCandidate) totalFee() *big.Int {
+	total := big.NewInt(0)
+	for _, tx := range c.txs {
+		total.Add(total, scaleFee(tx.AbsoluteFee))
+	}
+	return total
+}
+
+// worthCreating reports whether c's accumulated fee revenue justifies
+// spending an L1CoordinatorTx slot on it now, rather than deferring to a
+// later batch in the lookahead horizon where more of the same receiver's
+// txs may have arrived
+func (c *creationCandidate) worthCreating() bool {
+	return c.totalFee().Cmp(big.NewInt(l1SlotFeeCost)) >= 0
+}
+
+// PlanBatches simulates the next k batches starting at batchNum, resolving
+// the current mismatch where GetL1L2TxSelection greedily spends an
+// L1CoordinatorTx slot on every pending L2Tx addressed to an unresolved
+// ToEthAddr/ToBJJ receiver, even when that receiver's combined L2 fee
+// revenue doesn't justify the cost of the slot.
+//
+// On each lookahead step, txs addressed to a not-yet-existing receiver are
+// grouped by creationCandidate; a group is only given its
+// CreateAccountDeposit L1CoordinatorTx, and its txs admitted into the
+// batch, once its accumulated fee passes l1SlotFeeCost. Groups that don't
+// clear that bar are deferred to the next step of the horizon, so the L1
+// slot cost is amortized across however many of the horizon's batches it
+// takes for the receiver's revenue to add up; a group still under the bar
+// when the horizon ends is dropped, exactly as an unresolvable tx is
+// dropped today, and is logged accordingly.
+//
+// Simplification: PlanBatches plans against the L2DB pool as it exists
+// right now; it does not predict txs that will arrive mid-horizon, so
+// deferring a candidate only helps when its existing pending txs
+// accumulate across steps, or its own first tx alone already clears the
+// bar. The localAccountsDB is advanced one batch per step through the same
+// ProcessTxs+MakeCheckpoint path GetL1L2TxSelection uses, so each step is
+// selected against the state the previous planned steps leave behind; the
+// selection itself differs from GetL1L2TxSelection's (deferral above, and
+// no coordinator fee accounts are provisioned). If
+// selectionConfig.ProcessTxsConfig's capacity (MaxTx, MaxL1Tx,
+// MaxFeeTx) is violated at any step, every step's checkpoint taken so far
+// in this horizon is rolled back via Reset, and the error is returned
+// alongside the plans computed up to (but not including) the failing
+// step.
+func (txsel *TxSelector) PlanBatches(selectionConfig *SelectionConfig,
+	batchNum common.BatchNum, k uint16) ([]BatchPlan, error) {
+	if err := txsel.Reset(batchNum); err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	deferred := make(map[string]*creationCandidate)
+	plans := make([]BatchPlan, 0, k)
+	for i := uint16(1); i <= k; i++ {
+		currentBatch := batchNum + common.BatchNum(i)
+		plan, stillDeferred, err := txsel.planOneBatch(selectionConfig, currentBatch, deferred,
+			i == k)
+		if err != nil {
+			if resetErr := txsel.Reset(batchNum); resetErr != nil {
+				return nil, tracerr.Wrap(resetErr)
+			}
+			return plans, tracerr.Wrap(err)
+		}
+		deferred = stillDeferred
+		plans = append(plans, *plan)
+	}
+	for key, c := range deferred {
+		log.Debugw("PlanBatches: creationCandidate never reached l1SlotFeeCost, dropping",
+			"key", key, "totalFee", c.totalFee().String(), "nTxs", len(c.txs))
+	}
+	return plans, nil
+}
+
+// planOneBatch runs the selection for a single lookahead step, amortizing
+// creation candidates via deferred, and advances txsel.localAccountsDB
+// exactly like GetL1L2TxSelection does for a real batch
+func (txsel *TxSelector) planOneBatch(selectionConfig *SelectionConfig, batchNum common.BatchNum,
+	deferred map[string]*creationCandidate, lastStep bool) (*BatchPlan, map[string]*creationCandidate, error) {
+	coordIdxsMap, err := txsel.GetCoordIdxs()
+	if err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+	var coordIdxs []common.Idx
+	for tokenID := range coordIdxsMap {
+		coordIdxs = append(coordIdxs, coordIdxsMap[tokenID])
+	}
+
+	l2TxsRaw, err := txsel.l2db.GetPendingTxs()
+	if err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+
+	candidates := make(map[string]*creationCandidate)
+	for key, c := range deferred {
+		candidates[key] = c
+	}
+
+	var validTxs txs
+	var l1CoordinatorTxs []common.L1Tx
+	positionL1 := 0
+
+	for i := 0; i < len(l2TxsRaw); i++ {
+		l2Tx := l2TxsRaw[i]
+		switch {
+		case l2Tx.ToIdx == 0:
+			// account may already exist from a prior step's
+			// CreateAccountDeposit; reuse the existing resolution
+			// path for that case
+			resolvedTxs, updatedL1CoordinatorTxs, _, resolveErr :=
+				txsel.processTxToEthAddrBJJ(txs{}, l1CoordinatorTxs, positionL1, l2Tx)
+			if resolveErr == nil && len(updatedL1CoordinatorTxs) == len(l1CoordinatorTxs) {
+				// resolved without needing a new
+				// L1CoordinatorTx: account already existed
+				validTxs = append(validTxs, resolvedTxs...)
+				continue
+			}
+			c := groupCandidate(candidates, l2Tx)
+			c.txs = append(c.txs, l2Tx)
+		case l2Tx.ToIdx >= common.IdxUserThreshold:
+			if _, err := txsel.localAccountsDB.GetAccount(l2Tx.ToIdx); err != nil {
+				log.Debugw("invalid L2Tx: ToIdx not found in StateDB",
+					"ToIdx", l2Tx.ToIdx)
+				continue
+			}
+			validTxs = append(validTxs, l2Tx)
+		case l2Tx.ToIdx == common.Idx(1):
+			validTxs = append(validTxs, l2Tx)
+		}
+	}
+
+	stillDeferred := make(map[string]*creationCandidate)
+	for key, c := range candidates {
+		if !c.worthCreating() {
+			// kept either way: before the last step it may still
+			// clear the bar once more of the receiver's txs arrive;
+			// after the last step, what is left here is what
+			// PlanBatches logs as dropped
+			stillDeferred[key] = c
+			continue
+		}
+		resolvedTxs, updatedL1CoordinatorTxs, updatedPositionL1, resolveErr :=
+			txsel.processTxToEthAddrBJJ(txs{}, l1CoordinatorTxs, positionL1, c.txs[0])
+		if resolveErr != nil {
+			log.Debug(resolveErr)
+			continue
+		}
+		l1CoordinatorTxs = updatedL1CoordinatorTxs
+		positionL1 = updatedPositionL1
+		validTxs = append(validTxs, resolvedTxs...)
+		validTxs = append(validTxs, c.txs[1:]...)
+	}
+
+	compaction := txsel.senderQueueCompact(validTxs, selectionConfig.MaxTxsPerSender)
+	maxL2Txs := selectionConfig.ProcessTxsConfig.MaxTx - uint32(len(l1CoordinatorTxs))
+	l2Txs := txsel.getL2Profitable(compaction.admitted, maxL2Txs, selectionConfig)
+
+	_, err = txsel.localAccountsDB.ProcessTxs(selectionConfig.ProcessTxsConfig, coordIdxs, nil,
+		l1CoordinatorTxs, l2Txs)
+	if err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+	if err := txsel.localAccountsDB.MakeCheckpoint(); err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+
+	return &BatchPlan{
+		BatchNum:         batchNum,
+		CoordIdxs:        coordIdxs,
+		L1CoordinatorTxs: l1CoordinatorTxs,
+		L2Txs:            l2Txs,
+	}, stillDeferred, nil
+}
+
+func groupCandidate(candidates map[string]*creationCandidate, l2Tx common.PoolL2Tx) *creationCandidate {
+	c := &creationCandidate{toEthAddr: l2Tx.ToEthAddr, toBJJ: l2Tx.ToBJJ, tokenID: l2Tx.TokenID}
+	key := c.key()
+	if existing, ok := candidates[key]; ok {
+		return existing
+	}
+	candidates[key] = c
+	return c
+}
@@ -0,0 +1,183 @@
+package txselector
+
+import (
+	"math/big"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+)
+
+// feeScale converts a PoolL2Tx's float64 AbsoluteFee into a *big.Int score
+// with enough precision to compare meaningfully, since SelectionPolicy.Score
+// must return a *big.Int
+const feeScale = 1e9
+
+// PolicyState is the per-call context passed to every SelectionPolicy hook
+// for a single GetL1L2TxSelection invocation
+type PolicyState struct {
+	// ProcessTxsConfig is the batch's capacity budget (NLevels, MaxTx,
+	// MaxL1Tx, MaxFeeTx), needed by policies that weight a tx's fee
+	// against how much of that budget it consumes
+	ProcessTxsConfig statedb.ProcessTxsConfig
+}
+
+// SelectionPolicy picks and orders which candidate L2 txs getL2Profitable
+// includes in a batch. Built-in policies below cover the profitability
+// tradeoffs a coordinator operator might want to experiment with, without
+// forking TxSelector.
+type SelectionPolicy interface {
+	// Prepare is called once per GetL1L2TxSelection call, before any
+	// Score call, so a policy can reset per-batch state (e.g. a
+	// fairness policy's per-sender counters)
+	Prepare(candidates []common.PoolL2Tx, state PolicyState)
+	// Score ranks tx for inclusion; candidates are selected highest
+	// score first, up to the batch's L2 tx budget
+	Score(tx common.PoolL2Tx, state PolicyState) *big.Int
+	// Finalize runs on the txs remaining after scoring and truncation,
+	// letting a policy apply ordering constraints Score alone can't
+	// express (e.g. round-robining senders). Implementations must keep
+	// the sequential-nonce invariant per FromIdx required for zk-proof
+	// generation: if tx A is before tx B in the input and they share a
+	// FromIdx, A must stay before B in the output.
+	Finalize(selected []common.PoolL2Tx, state PolicyState) []common.PoolL2Tx
+}
+
+// noopFinalize is embedded by policies that don't need to reorder past
+// what Score already achieves
+type noopFinalize struct{}
+
+func (noopFinalize) Finalize(selected []common.PoolL2Tx, state PolicyState) []common.PoolL2Tx {
+	return selected
+}
+
+// AbsoluteFeePolicy scores by raw AbsoluteFee, with no regard for how much
+// of the batch's capacity a tx consumes. This is TxSelector's original,
+// and still default, behavior.
+type AbsoluteFeePolicy struct{ noopFinalize }
+
+// Prepare is a no-op for AbsoluteFeePolicy
+func (p *AbsoluteFeePolicy) Prepare([]common.PoolL2Tx, PolicyState) {}
+
+// Score returns tx.AbsoluteFee scaled to a *big.Int
+func (p *AbsoluteFeePolicy) Score(tx common.PoolL2Tx, _ PolicyState) *big.Int {
+	return scaleFee(tx.AbsoluteFee)
+}
+
+// FeePerBytePolicy scores by AbsoluteFee divided by the tx's approximate
+// data-availability footprint, favoring compact txs over large ones that
+// happen to pay a similar absolute fee
+type FeePerBytePolicy struct{ noopFinalize }
+
+// Prepare is a no-op for FeePerBytePolicy
+func (p *FeePerBytePolicy) Prepare([]common.PoolL2Tx, PolicyState) {}
+
+// Score returns tx.AbsoluteFee / dataAvailabilityBytes(tx), scaled
+func (p *FeePerBytePolicy) Score(tx common.PoolL2Tx, _ PolicyState) *big.Int {
+	return new(big.Int).Div(scaleFee(tx.AbsoluteFee), big.NewInt(int64(dataAvailabilityBytes(tx))))
+}
+
+// FeePerComputationUnitPolicy scores by AbsoluteFee divided by how many
+// computation units (see computationUnits) the tx consumes against the
+// batch's MaxTx/MaxL1Tx/MaxFeeTx budgets, favoring cheap-to-forge txs
+type FeePerComputationUnitPolicy struct{ noopFinalize }
+
+// Prepare is a no-op for FeePerComputationUnitPolicy
+func (p *FeePerComputationUnitPolicy) Prepare([]common.PoolL2Tx, PolicyState) {}
+
+// Score returns tx.AbsoluteFee / computationUnits(tx, state), scaled
+func (p *FeePerComputationUnitPolicy) Score(tx common.PoolL2Tx, state PolicyState) *big.Int {
+	return new(big.Int).Div(scaleFee(tx.AbsoluteFee), computationUnits(tx, state.ProcessTxsConfig))
+}
+
+// PerSenderFairnessPolicy scores txs like Inner (AbsoluteFeePolicy when
+// nil), but reorders the post-truncation selection to round-robin between
+// senders, so no sender's txs are all clustered together. Which txs make
+// the cut is decided by Inner's scores alone: it does not limit how many
+// slots a sender gets (SelectionConfig.MaxTxsPerSender does)
+type PerSenderFairnessPolicy struct {
+	// Inner scores candidates before the top-N cut; defaults to
+	// AbsoluteFeePolicy when nil
+	Inner SelectionPolicy
+}
+
+// Prepare delegates to Inner
+func (p *PerSenderFairnessPolicy) Prepare(candidates []common.PoolL2Tx, state PolicyState) {
+	p.inner().Prepare(candidates, state)
+}
+
+// Score delegates to Inner
+func (p *PerSenderFairnessPolicy) Score(tx common.PoolL2Tx, state PolicyState) *big.Int {
+	return p.inner().Score(tx, state)
+}
+
+// Finalize round-robins selected across distinct FromIdx senders, pulling
+// one tx per sender per round in each sender's original (nonce-sorted)
+// relative order, so no sender's txs all end up clustered together
+func (p *PerSenderFairnessPolicy) Finalize(selected []common.PoolL2Tx, _ PolicyState) []common.PoolL2Tx {
+	bySender := make(map[common.Idx][]common.PoolL2Tx)
+	var order []common.Idx
+	for _, tx := range selected {
+		if _, ok := bySender[tx.FromIdx]; !ok {
+			order = append(order, tx.FromIdx)
+		}
+		bySender[tx.FromIdx] = append(bySender[tx.FromIdx], tx)
+	}
+
+	out := make([]common.PoolL2Tx, 0, len(selected))
+	for {
+		progressed := false
+		for _, idx := range order {
+			if len(bySender[idx]) == 0 {
+				continue
+			}
+			out = append(out, bySender[idx][0])
+			bySender[idx] = bySender[idx][1:]
+			progressed = true
+		}
+		if !progressed {
+			break
+		}
+	}
+	return out
+}
+
+func (p *PerSenderFairnessPolicy) inner() SelectionPolicy {
+	if p.Inner == nil {
+		return &AbsoluteFeePolicy{}
+	}
+	return p.Inner
+}
+
+// dataAvailabilityBytes approximates how many bytes tx contributes to the
+// batch's L1 data-availability payload: a resolved tx (ToIdx already
+// exists) only carries fromIdx+toIdx+amount+fee, while one still needing a
+// ToEthAddr/ToBJJ account creation additionally carries that account's
+// public key or address
+func dataAvailabilityBytes(tx common.PoolL2Tx) int {
+	const (
+		txDataBytesResolved   = 8  //nolint:gomnd // fromIdx(2)+toIdx(2)+amountFloat(2)+fee(1)+misc(1)
+		txDataBytesUnresolved = 41 //nolint:gomnd // + compressed BJJ/EthAddr for the new account
+	)
+	if tx.ToIdx == 0 {
+		return txDataBytesUnresolved
+	}
+	return txDataBytesResolved
+}
+
+// computationUnits approximates a tx's relative cost against the batch's
+// MaxTx/MaxL1Tx/MaxFeeTx budgets: every tx costs one MaxTx slot, and a tx
+// that still needs an L1CoordinatorTx (ToIdx==0) additionally costs an
+// MaxL1Tx slot, weighted by how scarce L1 slots are relative to L2 ones
+func computationUnits(tx common.PoolL2Tx, cfg statedb.ProcessTxsConfig) *big.Int {
+	units := big.NewInt(1)
+	if tx.ToIdx == 0 && cfg.MaxL1Tx > 0 {
+		units.Add(units, new(big.Int).Div(big.NewInt(int64(cfg.MaxTx)), big.NewInt(int64(cfg.MaxL1Tx))))
+	}
+	return units
+}
+
+func scaleFee(fee float64) *big.Int {
+	scaled := new(big.Float).Mul(big.NewFloat(fee), big.NewFloat(feeScale))
+	i, _ := scaled.Int(nil)
+	return i
+}
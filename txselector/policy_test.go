@@ -0,0 +1,103 @@
+package txselector
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/hermeznetwork/hermez-node/common"
+	"github.com/hermeznetwork/hermez-node/db/statedb"
+	"github.com/stretchr/testify/assert"
+)
+
+func poolTx(fromIdx, toIdx common.Idx, nonce common.Nonce, fee float64) common.PoolL2Tx {
+	return common.PoolL2Tx{
+		FromIdx:     fromIdx,
+		ToIdx:       toIdx,
+		Nonce:       nonce,
+		AbsoluteFee: fee,
+	}
+}
+
+func scoreAll(t *testing.T, policy SelectionPolicy, state PolicyState, candidates []common.PoolL2Tx) []*big.Int {
+	t.Helper()
+	policy.Prepare(candidates, state)
+	scores := make([]*big.Int, len(candidates))
+	for i, tx := range candidates {
+		scores[i] = policy.Score(tx, state)
+	}
+	return scores
+}
+
+func TestAbsoluteFeePolicyOrdering(t *testing.T) {
+	candidates := []common.PoolL2Tx{
+		poolTx(256, 257, 0, 1), // lowest fee
+		poolTx(258, 259, 0, 3), // highest fee
+		poolTx(260, 261, 0, 2), // middle fee
+	}
+	scores := scoreAll(t, &AbsoluteFeePolicy{}, PolicyState{}, candidates)
+	assert.True(t, scores[1].Cmp(scores[2]) > 0)
+	assert.True(t, scores[2].Cmp(scores[0]) > 0)
+}
+
+func TestFeePerBytePolicyFavorsResolvedTxs(t *testing.T) {
+	// same AbsoluteFee, but one tx still needs an L1CoordinatorTx
+	// (ToIdx==0), so it should score lower per data-availability byte
+	resolved := poolTx(256, 257, 0, 1)
+	unresolved := poolTx(256, 0, 0, 1)
+	scores := scoreAll(t, &FeePerBytePolicy{}, PolicyState{}, []common.PoolL2Tx{resolved, unresolved})
+	assert.True(t, scores[0].Cmp(scores[1]) > 0)
+}
+
+func TestFeePerComputationUnitPolicyFavorsResolvedTxs(t *testing.T) {
+	state := PolicyState{ProcessTxsConfig: statedb.ProcessTxsConfig{MaxTx: 100, MaxL1Tx: 10}}
+	resolved := poolTx(256, 257, 0, 1)
+	unresolved := poolTx(256, 0, 0, 1)
+	scores := scoreAll(t, &FeePerComputationUnitPolicy{}, state, []common.PoolL2Tx{resolved, unresolved})
+	assert.True(t, scores[0].Cmp(scores[1]) > 0)
+}
+
+func TestPerSenderFairnessPolicyRoundRobins(t *testing.T) {
+	// sender A has 2 txs, sender B has 1; A's txs must keep their
+	// relative (nonce) order, but B must not end up stuck behind both
+	// of A's txs
+	selected := []common.PoolL2Tx{
+		poolTx(256, 257, 0, 1), // A, nonce 0
+		poolTx(258, 259, 0, 1), // B, nonce 0
+		poolTx(256, 257, 1, 1), // A, nonce 1
+	}
+	policy := &PerSenderFairnessPolicy{}
+	out := policy.Finalize(selected, PolicyState{})
+	assert.Len(t, out, 3)
+	assert.Equal(t, common.Idx(256), out[0].FromIdx)
+	assert.Equal(t, common.Idx(258), out[1].FromIdx)
+	assert.Equal(t, common.Idx(256), out[2].FromIdx)
+	assert.Equal(t, common.Nonce(0), out[0].Nonce)
+	assert.Equal(t, common.Nonce(1), out[2].Nonce)
+}
+
+// TestGetL2ProfitablePreservesNonceOrderPerAccount confirms that
+// regardless of which SelectionPolicy orders/truncates the candidates,
+// getL2Profitable's output keeps each FromIdx's txs in nonce order, since
+// the zkproof generation requires sequential nonces per account.
+func TestGetL2ProfitablePreservesNonceOrderPerAccount(t *testing.T) {
+	txsel := &TxSelector{defaultPolicy: &AbsoluteFeePolicy{}}
+	candidates := txs{
+		poolTx(256, 257, 1, 3), // A, nonce 1, high fee
+		poolTx(256, 257, 0, 1), // A, nonce 0, low fee
+		poolTx(258, 259, 0, 2), // B, nonce 0
+	}
+	selectionConfig := &SelectionConfig{}
+	out := txsel.getL2Profitable(candidates, 10, selectionConfig) //nolint:gomnd
+
+	var lastNonceA *common.Nonce
+	for _, tx := range out {
+		if tx.FromIdx != common.Idx(256) {
+			continue
+		}
+		if lastNonceA != nil {
+			assert.True(t, tx.Nonce > *lastNonceA)
+		}
+		nonce := tx.Nonce
+		lastNonceA = &nonce
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"strings"
 
 	ethCommon "github.com/ethereum/go-ethereum/common"
 	"github.com/hermeznetwork/hermez-node/common"
@@ -24,19 +25,10 @@ const (
 	PathCoordIdxsDB = "/coordidxs"
 )
 
-// txs implements the interface Sort for an array of Tx
+// txs is an array of Tx, ordered and scored by getL2Profitable's
+// SelectionPolicy rather than a fixed sort.Interface
 type txs []common.PoolL2Tx
 
-func (t txs) Len() int {
-	return len(t)
-}
-func (t txs) Swap(i, j int) {
-	t[i], t[j] = t[j], t[i]
-}
-func (t txs) Less(i, j int) bool {
-	return t[i].AbsoluteFee > t[j].AbsoluteFee
-}
-
 // CoordAccount contains the data of the Coordinator account, that will be used
 // to create new transactions of CreateAccountDeposit type to add new TokenID
 // accounts for the Coordinator to receive the fees.
@@ -56,6 +48,22 @@ type SelectionConfig struct {
 
 	// ProcessTxsConfig contains the config for ProcessTxs
 	ProcessTxsConfig statedb.ProcessTxsConfig
+
+	// Policy overrides, for this selection only, which SelectionPolicy
+	// ranks and orders candidate L2 txs. When nil, the TxSelector's
+	// default policy (set via NewTxSelector) is used.
+	Policy SelectionPolicy
+
+	// MaxTxsPerSender caps how many L2 txs from a single FromIdx can be
+	// admitted into a batch, so one account can't monopolize MaxTx slots
+	// at the expense of every other sender. 0 means no cap.
+	MaxTxsPerSender uint32
+
+	// FeeTokenPolicy controls which TokenIDs the coordinator is allowed
+	// to auto-provision an Idx for when the batch collects fees in a
+	// TokenID it doesn't have an account for yet. nil allows every
+	// TokenID, regardless of expected fee.
+	FeeTokenPolicy *FeeTokenPolicy
 }
 
 // TxSelector implements all the functionalities to select the txs for the next
@@ -66,11 +74,17 @@ type TxSelector struct {
 
 	coordAccount *CoordAccount
 	coordIdxsDB  *pebble.PebbleStorage
+	auditLogDB   *pebble.PebbleStorage
+
+	defaultPolicy SelectionPolicy
 }
 
-// NewTxSelector returns a *TxSelector
+// NewTxSelector returns a *TxSelector. defaultPolicy is used by
+// GetL1L2TxSelection whenever a call's SelectionConfig.Policy is nil; pass
+// nil to keep the original absolute-fee-max behavior.
 func NewTxSelector(coordAccount *CoordAccount, dbpath string,
-	synchronizerStateDB *statedb.StateDB, l2 *l2db.L2DB) (*TxSelector, error) {
+	synchronizerStateDB *statedb.StateDB, l2 *l2db.L2DB,
+	defaultPolicy SelectionPolicy) (*TxSelector, error) {
 	localAccountsDB, err := statedb.NewLocalStateDB(dbpath,
 		synchronizerStateDB, statedb.TypeTxSelector, 0) // without merkletree
 	if err != nil {
@@ -82,11 +96,22 @@ func NewTxSelector(coordAccount *CoordAccount, dbpath string,
 		return nil, tracerr.Wrap(err)
 	}
 
+	auditLogDB, err := pebble.NewPebbleStorage(dbpath+PathAuditLogDB, false)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+
+	if defaultPolicy == nil {
+		defaultPolicy = &AbsoluteFeePolicy{}
+	}
+
 	return &TxSelector{
 		l2db:            l2,
 		localAccountsDB: localAccountsDB,
 		coordAccount:    coordAccount,
 		coordIdxsDB:     coordIdxsDB,
+		auditLogDB:      auditLogDB,
+		defaultPolicy:   defaultPolicy,
 	}, nil
 }
 
@@ -157,10 +182,35 @@ func (txsel *TxSelector) GetL2TxSelection(selectionConfig *SelectionConfig,
 	return coordIdxs, l1CoordinatorTxs, l2Txs, tracerr.Wrap(err)
 }
 
-// GetL1L2TxSelection returns the selection of L1 + L2 txs
+// GetL1L2TxSelection returns the selection of L1 + L2 txs, sourcing its
+// pending L2 txs from the live pool (txsel.l2db.GetPendingTxs)
 func (txsel *TxSelector) GetL1L2TxSelection(selectionConfig *SelectionConfig,
 	batchNum common.BatchNum, l1Txs []common.L1Tx) ([]common.Idx, []common.L1Tx, []common.L1Tx,
 	[]common.PoolL2Tx, error) {
+	l2TxsRaw, err := txsel.l2db.GetPendingTxs() // (batchID)
+	if err != nil {
+		return nil, nil, nil, nil, tracerr.Wrap(err)
+	}
+	return txsel.getL1L2TxSelectionFromPool(selectionConfig, batchNum, l1Txs, l2TxsRaw)
+}
+
+// GetL1L2TxSelectionWithPool behaves like GetL1L2TxSelection, but selects
+// from pool instead of the live l2db pool: Replay uses this to verify a
+// selection is deterministic against the exact pool txs an AuditRecord was
+// originally computed against, rather than whatever the live pool looks
+// like now.
+func (txsel *TxSelector) GetL1L2TxSelectionWithPool(selectionConfig *SelectionConfig,
+	batchNum common.BatchNum, l1Txs []common.L1Tx, pool []common.PoolL2Tx) ([]common.Idx,
+	[]common.L1Tx, []common.L1Tx, []common.PoolL2Tx, error) {
+	return txsel.getL1L2TxSelectionFromPool(selectionConfig, batchNum, l1Txs, pool)
+}
+
+// getL1L2TxSelectionFromPool is the shared implementation of
+// GetL1L2TxSelection and GetL1L2TxSelectionWithPool, selecting from the
+// given l2TxsRaw pool rather than sourcing it itself
+func (txsel *TxSelector) getL1L2TxSelectionFromPool(selectionConfig *SelectionConfig,
+	batchNum common.BatchNum, l1Txs []common.L1Tx, l2TxsRaw []common.PoolL2Tx) ([]common.Idx,
+	[]common.L1Tx, []common.L1Tx, []common.PoolL2Tx, error) {
 	// apply l1-user-tx to localAccountDB
 	//     create new leaves
 	//     update balances
@@ -176,14 +226,9 @@ func (txsel *TxSelector) GetL1L2TxSelection(selectionConfig *SelectionConfig,
 		coordIdxs = append(coordIdxs, coordIdxsMap[tokenID])
 	}
 
-	// get pending l2-tx from tx-pool
-	l2TxsRaw, err := txsel.l2db.GetPendingTxs() // (batchID)
-	if err != nil {
-		return nil, nil, nil, nil, tracerr.Wrap(err)
-	}
-
 	var validTxs txs
 	var l1CoordinatorTxs []common.L1Tx
+	var rejectedTxs []AuditRejectedTx
 	positionL1 := len(l1Txs)
 
 	for i := 0; i < len(l2TxsRaw); i++ {
@@ -201,6 +246,11 @@ func (txsel *TxSelector) GetL1L2TxSelection(selectionConfig *SelectionConfig,
 					positionL1, l2TxsRaw[i])
 			if err != nil {
 				log.Debug(err)
+				rejectedTxs = append(rejectedTxs, AuditRejectedTx{
+					TxID:   l2TxsRaw[i].TxID,
+					Reason: rejectReasonFromErr(err),
+					Detail: err.Error(),
+				})
 			}
 		} else if l2TxsRaw[i].ToIdx >= common.IdxUserThreshold {
 			_, err = txsel.localAccountsDB.GetAccount(l2TxsRaw[i].ToIdx)
@@ -208,6 +258,11 @@ func (txsel *TxSelector) GetL1L2TxSelection(selectionConfig *SelectionConfig,
 				// tx not valid
 				log.Debugw("invalid L2Tx: ToIdx not found in StateDB",
 					"ToIdx", l2TxsRaw[i].ToIdx)
+				rejectedTxs = append(rejectedTxs, AuditRejectedTx{
+					TxID:   l2TxsRaw[i].TxID,
+					Reason: ReasonToIdxNotFound,
+					Detail: err.Error(),
+				})
 				continue
 			}
 
@@ -221,9 +276,29 @@ func (txsel *TxSelector) GetL1L2TxSelection(selectionConfig *SelectionConfig,
 		}
 	}
 
+	// compact each sender's queue down to the longest Nonce-contiguous
+	// prefix admissible against its current account Nonce, capped at
+	// selectionConfig.MaxTxsPerSender, before ranking for profitability
+	compaction := txsel.senderQueueCompact(validTxs, selectionConfig.MaxTxsPerSender)
+	for _, tx := range compaction.nonceGapped {
+		rejectedTxs = append(rejectedTxs, AuditRejectedTx{TxID: tx.TxID, Reason: ReasonNonceGap})
+	}
+	for _, tx := range compaction.capExceeded {
+		rejectedTxs = append(rejectedTxs, AuditRejectedTx{TxID: tx.TxID, Reason: ReasonCapacityExceeded})
+	}
+
 	// get most profitable L2-tx
 	maxL2Txs := selectionConfig.ProcessTxsConfig.MaxTx - uint32(len(l1CoordinatorTxs)) // - len(l1UserTxs) // TODO if there are L1UserTxs take them in to account
-	l2Txs := txsel.getL2Profitable(validTxs, maxL2Txs)
+	l2Txs := txsel.getL2Profitable(compaction.admitted, maxL2Txs, selectionConfig)
+	rejectedTxs = append(rejectedTxs, capacityExceededRejections(compaction.admitted, l2Txs)...)
+
+	// auto-provision a coordinator Idx, via a CreateAccountDeposit
+	// L1CoordinatorTx, for every TokenID l2Txs pays fees in that the
+	// coordinator doesn't have an account for yet and that
+	// selectionConfig.FeeTokenPolicy allows
+	l1CoordinatorTxs = append(l1CoordinatorTxs,
+		txsel.provisionCoordinatorAccounts(l2Txs, coordIdxsMap, selectionConfig.FeeTokenPolicy,
+			positionL1)...)
 
 	//nolint:gomnd
 	ptc := statedb.ProcessTxsConfig{ // TODO TMP
@@ -233,7 +308,7 @@ func (txsel *TxSelector) GetL1L2TxSelection(selectionConfig *SelectionConfig,
 		MaxL1Tx:  64,
 	}
 	// process the txs in the local AccountsDB
-	_, err = txsel.localAccountsDB.ProcessTxs(ptc, coordIdxs, l1Txs, l1CoordinatorTxs, l2Txs)
+	ptOut, err := txsel.localAccountsDB.ProcessTxs(ptc, coordIdxs, l1Txs, l1CoordinatorTxs, l2Txs)
 	if err != nil {
 		return nil, nil, nil, nil, tracerr.Wrap(err)
 	}
@@ -242,9 +317,79 @@ func (txsel *TxSelector) GetL1L2TxSelection(selectionConfig *SelectionConfig,
 		return nil, nil, nil, nil, tracerr.Wrap(err)
 	}
 
+	// only now that ProcessTxs and MakeCheckpoint have both succeeded is
+	// it safe to persist the newly auto-provisioned coordinator Idxs: if
+	// ProcessTxs had failed, the function already returned above and
+	// coordIdxsDB was never touched, so a failed batch can't leave a
+	// partially-created coordinator account behind
+	newCoordIdxs := txsel.newCoordIdxsFromCreatedAccounts(ptOut.CreatedAccounts)
+	if len(newCoordIdxs) > 0 {
+		if err := txsel.AddCoordIdxs(newCoordIdxs); err != nil {
+			return nil, nil, nil, nil, tracerr.Wrap(err)
+		}
+	}
+
+	digest, digestErr := l1TxsDigest(l1Txs)
+	if digestErr != nil {
+		log.Errorw("auditlog: l1TxsDigest", "err", digestErr)
+	}
+	poolTxIDs := make([]common.TxID, len(l2TxsRaw))
+	for i, tx := range l2TxsRaw {
+		poolTxIDs[i] = tx.TxID
+	}
+	acceptedTxIDs := make([]common.TxID, len(l2Txs))
+	for i, tx := range l2Txs {
+		acceptedTxIDs[i] = tx.TxID
+	}
+	txsel.logSelection(&AuditRecord{
+		BatchNum:      batchNum,
+		L1TxsDigest:   digest,
+		PoolTxIDs:     poolTxIDs,
+		CoordIdxs:     coordIdxs,
+		Config:        *selectionConfig,
+		AcceptedTxIDs: acceptedTxIDs,
+		RejectedTxs:   rejectedTxs,
+	})
+
 	return nil, l1Txs, l1CoordinatorTxs, l2Txs, nil
 }
 
+// rejectReasonFromErr classifies a processTxToEthAddrBJJ error into a
+// RejectReason for the audit log. processTxToEthAddrBJJ doesn't return a
+// typed error, so this is a best-effort classification based on the error
+// message it builds.
+func rejectReasonFromErr(err error) RejectReason {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "ToEthAddr & ToBJJ found in AccountCreationAuths"):
+		return ReasonBJJMismatch
+	case strings.Contains(msg, "AccountCreationAuths"):
+		return ReasonAuthMissing
+	default:
+		return ReasonToIdxNotFound
+	}
+}
+
+// capacityExceededRejections returns an AuditRejectedTx with
+// ReasonCapacityExceeded for every tx in validTxs that getL2Profitable
+// didn't include in l2Txs
+func capacityExceededRejections(validTxs txs, l2Txs []common.PoolL2Tx) []AuditRejectedTx {
+	included := make(map[common.TxID]bool, len(l2Txs))
+	for _, tx := range l2Txs {
+		included[tx.TxID] = true
+	}
+	var rejected []AuditRejectedTx
+	for _, tx := range validTxs {
+		if !included[tx.TxID] {
+			rejected = append(rejected, AuditRejectedTx{
+				TxID:   tx.TxID,
+				Reason: ReasonCapacityExceeded,
+			})
+		}
+	}
+	return rejected
+}
+
 // processTxsToEthAddrBJJ process the common.PoolL2Tx in the case where
 // ToIdx==0, which can be the tx type of ToEthAddr or ToBJJ. If the receiver
 // does not have an account yet, a new L1CoordinatorTx of type
@@ -372,22 +517,81 @@ func checkAlreadyPendingToCreate(l1CoordinatorTxs []common.L1Tx,
 	return false
 }
 
-// getL2Profitable returns the profitable selection of L2Txssorted by Nonce
-func (txsel *TxSelector) getL2Profitable(txs txs, max uint32) txs {
-	sort.Sort(txs)
-	if len(txs) < int(max) {
-		return txs
-	}
-	txs = txs[:max]
-
-	// sort l2Txs by Nonce. This can be done in many different ways, what
-	// is needed is to output the txs where the Nonce of txs for each
-	// Account is sorted, but the txs can not be grouped by sender Account
-	// neither by Fee. This is because later on the Nonces will need to be
-	// sequential for the zkproof generation.
-	sort.SliceStable(txs, func(i, j int) bool {
-		return txs[i].Nonce < txs[j].Nonce
+// getL2Profitable returns the selection of L2Txs ranked and ordered by
+// selectionConfig.Policy (or txsel.defaultPolicy, when Policy is nil),
+// sorted by Nonce so the Nonce of txs for each Account stays in order,
+// since later on the Nonces need to be sequential for the zkproof
+// generation. candidates is expected to already be each sender's
+// Nonce-contiguous admissible prefix (see senderQueueCompact); ranking and
+// truncation happen per-sender group, not per-tx, so a single high-fee tx
+// can't pull the rest of its sender's queue ahead of a more profitable one.
+func (txsel *TxSelector) getL2Profitable(candidates txs, max uint32, selectionConfig *SelectionConfig) txs {
+	policy := selectionConfig.Policy
+	if policy == nil {
+		policy = txsel.defaultPolicy
+	}
+	state := PolicyState{ProcessTxsConfig: selectionConfig.ProcessTxsConfig}
+	policy.Prepare(candidates, state)
+
+	// score and truncate at the per-sender group level (sum of Score
+	// over the group), not per-tx, so a single high-fee tx doesn't pull
+	// in the rest of its sender's queue ahead of a more profitable
+	// sender, and so a sender's queue (expected to already be the
+	// nonce-contiguous admissible prefix, e.g. from senderQueueCompact)
+	// is never split except to fit the remaining budget
+	groups := groupByFromIdx(candidates)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groupScore(groups[i], policy, state).Cmp(groupScore(groups[j], policy, state)) > 0
+	})
+
+	var scored txs
+	for _, group := range groups {
+		if uint32(len(scored)) >= max {
+			break
+		}
+		remaining := int(max) - len(scored)
+		if remaining >= len(group) {
+			scored = append(scored, group...)
+			continue
+		}
+		// group is already Nonce-ordered, so keeping its leading
+		// `remaining` txs preserves the sequential-nonce invariant
+		scored = append(scored, group[:remaining]...)
+	}
+
+	// this can be done in many different ways, what is needed is to
+	// output the txs where the Nonce of txs for each Account is sorted,
+	// but the txs can not be grouped by sender Account neither by Fee
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Nonce < scored[j].Nonce
 	})
 
-	return txs
+	return txs(policy.Finalize(scored, state))
+}
+
+// groupByFromIdx groups candidates by FromIdx, each group keeping
+// candidates' original relative order
+func groupByFromIdx(candidates txs) []txs {
+	bySender := make(map[common.Idx]txs)
+	var order []common.Idx
+	for _, tx := range candidates {
+		if _, ok := bySender[tx.FromIdx]; !ok {
+			order = append(order, tx.FromIdx)
+		}
+		bySender[tx.FromIdx] = append(bySender[tx.FromIdx], tx)
+	}
+	groups := make([]txs, len(order))
+	for i, fromIdx := range order {
+		groups[i] = bySender[fromIdx]
+	}
+	return groups
+}
+
+// groupScore returns the sum of policy.Score over every tx in group
+func groupScore(group txs, policy SelectionPolicy, state PolicyState) *big.Int {
+	total := big.NewInt(0)
+	for _, tx := range group {
+		total.Add(total, policy.Score(tx, state))
+	}
+	return total
 }